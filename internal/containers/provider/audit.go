@@ -0,0 +1,151 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+// AuditEntry is a single Docker API call made by the provider.
+type AuditEntry struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Method     string    `json:"method"`
+	Endpoint   string    `json:"endpoint"`
+	ResourceID string    `json:"resource_id,omitempty"`
+	// Outcome is the response status, or the error when no response was
+	// received.
+	Outcome  string `json:"outcome"`
+	Duration string `json:"duration"`
+}
+
+// auditResourceRe extracts the resource the endpoint operates on, e.g. the
+// container ID of /containers/{id}/start.
+var auditResourceRe = regexp.MustCompile(`^/(?:containers|volumes|networks|exec|images|plugins)/([^/]+)`)
+
+// auditCollectionEndpoints are the path segments that follow a resource type
+// without naming a resource.
+var auditCollectionEndpoints = map[string]bool{
+	"json":   true,
+	"create": true,
+	"prune":  true,
+	"load":   true,
+	"search": true,
+}
+
+// versionPrefixRe matches the API version the client prefixes paths with.
+var versionPrefixRe = regexp.MustCompile(`^/v[0-9.]+`)
+
+// auditHTTPTimeout bounds each post of the HTTPAuditWriter.
+const auditHTTPTimeout = 10 * time.Second
+
+// WithAuditLog logs every Docker API call as a JSON AuditEntry to w. Each entry
+// is written before the call returns, so none is lost when the provider exits,
+// and a failing writer never fails the API calls. Streams hijacked from an API
+// call, like exec and attach sessions, are dialed by the client outside of its
+// transport and are not logged. TLS connections to the Docker daemon are not
+// supported.
+func WithAuditLog(w io.Writer) client.Opt {
+	return func(c *client.Client) error {
+		hc := c.HTTPClient()
+		if tr, ok := hc.Transport.(*http.Transport); ok && tr.TLSClientConfig != nil {
+			// the client only keeps the TLS configuration around for hijacked
+			// connections when it owns the transport
+			return fmt.Errorf("audit logging is not supported for TLS connections to the Docker daemon")
+		}
+
+		hc.Transport = &auditTransport{base: hc.Transport, enc: json.NewEncoder(w)}
+		return client.WithHTTPClient(hc)(c)
+	}
+}
+
+type auditTransport struct {
+	base http.RoundTripper
+
+	// mu serializes the entries written with enc
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func (t *auditTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+
+	endpoint := versionPrefixRe.ReplaceAllString(req.URL.Path, "")
+	entry := AuditEntry{
+		Timestamp: start.UTC(),
+		Method:    req.Method,
+		Endpoint:  endpoint,
+		Duration:  time.Since(start).String(),
+	}
+	if m := auditResourceRe.FindStringSubmatch(endpoint); m != nil && !auditCollectionEndpoints[m[1]] {
+		entry.ResourceID = m[1]
+	}
+	if err != nil {
+		entry.Outcome = err.Error()
+	} else {
+		entry.Outcome = resp.Status
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// a failing writer must not affect the Docker API calls
+	_ = t.enc.Encode(entry)
+
+	return resp, err
+}
+
+// FileAuditWriter appends each audit entry to a file. The file is opened for
+// each entry so it is never left open.
+type FileAuditWriter struct {
+	Path string
+}
+
+func (w *FileAuditWriter) Write(p []byte) (int, error) {
+	f, err := os.OpenFile(w.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := f.Write(p)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return n, err
+}
+
+// HTTPAuditWriter posts each audit entry to an HTTP endpoint.
+type HTTPAuditWriter struct {
+	URL string
+}
+
+func (w *HTTPAuditWriter) Write(p []byte) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), auditHTTPTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(p))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return 0, fmt.Errorf("unexpected status %s from %s", resp.Status, w.URL)
+	}
+
+	return len(p), nil
+}
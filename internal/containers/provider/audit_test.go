@@ -0,0 +1,154 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/missing/json") {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	testCases := map[string]struct {
+		method string
+		path   string
+		want   AuditEntry
+	}{
+		"resource endpoint": {
+			method: http.MethodPost,
+			path:   "/v1.43/containers/abc/start",
+			want:   AuditEntry{Method: http.MethodPost, Endpoint: "/containers/abc/start", ResourceID: "abc", Outcome: "200 OK"},
+		},
+		"collection endpoint": {
+			method: http.MethodPost,
+			path:   "/v1.43/volumes/create",
+			want:   AuditEntry{Method: http.MethodPost, Endpoint: "/volumes/create", Outcome: "200 OK"},
+		},
+		"unversioned endpoint": {
+			method: http.MethodGet,
+			path:   "/_ping",
+			want:   AuditEntry{Method: http.MethodGet, Endpoint: "/_ping", Outcome: "200 OK"},
+		},
+		"failed call": {
+			method: http.MethodGet,
+			path:   "/v1.43/networks/missing/json",
+			want:   AuditEntry{Method: http.MethodGet, Endpoint: "/networks/missing/json", ResourceID: "missing", Outcome: "404 Not Found"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			hc := &http.Client{Transport: &auditTransport{base: http.DefaultTransport, enc: json.NewEncoder(&buf)}}
+
+			req, err := http.NewRequest(tc.method, srv.URL+tc.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := hc.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			// the entry is written by the time the call returns
+			var got AuditEntry
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("decoding audit entry %q: %v", buf.String(), err)
+			}
+			if got.Timestamp.IsZero() || got.Duration == "" {
+				t.Errorf("audit entry %+v has no timestamp or duration", got)
+			}
+			got.Timestamp, got.Duration = tc.want.Timestamp, tc.want.Duration
+			if got != tc.want {
+				t.Errorf("audit entry = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("backend down")
+}
+
+func TestAuditTransportFailingWriter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	hc := &http.Client{Transport: &auditTransport{base: http.DefaultTransport, enc: json.NewEncoder(failingWriter{})}}
+	resp, err := hc.Get(srv.URL + "/v1.43/info")
+	if err != nil {
+		t.Fatalf("call failed along with the audit writer: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "ok" {
+		t.Errorf("body = %q, want ok", body)
+	}
+}
+
+func TestHTTPAuditWriter(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		got = append(got, string(body))
+	}))
+	defer srv.Close()
+
+	w := &HTTPAuditWriter{URL: srv.URL}
+	if _, err := w.Write([]byte(`{"method":"GET"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != `{"method":"GET"}` {
+		t.Errorf("posted entries = %v", got)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	if _, err := (&HTTPAuditWriter{URL: failing.URL}).Write([]byte(`{}`)); err == nil {
+		t.Error("Write() to a failing endpoint succeeded")
+	}
+}
+
+func TestFileAuditWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	w := &FileAuditWriter{Path: path}
+
+	for _, line := range []string{"first\n", "second\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "first\nsecond\n" {
+		t.Errorf("audit log = %q, want both entries appended", got)
+	}
+}
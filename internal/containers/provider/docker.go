@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"path/filepath"
 	"sync"
 	"time"
@@ -56,6 +57,30 @@ type DockerRequest struct {
 	// ManagedVolumes is the list of volumes that should be torn down when the
 	// provider finishes execution
 	ManagedVolumes []mount.Mount
+	// VolumeRemover, when set, is called to remove the managed volumes instead
+	// of removing them directly
+	VolumeRemover func(ctx context.Context, name string) error
+	// ReadonlyRootfs mounts the container's root filesystem as read only
+	ReadonlyRootfs bool
+	// Tmpfs is a map of container paths to tmpfs mount options
+	Tmpfs map[string]string
+	// CapAdd and CapDrop are the kernel capabilities to add to and drop from
+	// the container
+	CapAdd  []string
+	CapDrop []string
+	// SecurityOpt is a list of security options, e.g. seccomp or apparmor
+	// profiles
+	SecurityOpt []string
+	// NetworkMode is the network mode used by Run, e.g. container:<name> to
+	// join the network namespace of another container. Defaults to the
+	// daemon's default network.
+	NetworkMode string
+	// DefaultNetwork is the bridge network the container is created in, created
+	// if it doesn't exist. Defaults to DockerDefaultNetworkName.
+	DefaultNetwork string
+	// PulledImages, when set, records the images pulled by any provider
+	// sharing it, so each image is only pulled once.
+	PulledImages *sync.Map
 }
 
 type DockerNetworkRequest struct {
@@ -68,14 +93,17 @@ type DockerNetworkRequest struct {
 type DockerClient struct {
 	*client.Client
 	mu sync.Mutex
+	// labels are attached to every object created with the client, on top of
+	// the DefaultLabels
+	labels map[string]string
 }
 
-func NewDockerClient() (*DockerClient, error) {
-	cli, err := client.NewClientWithOpts(
+func NewDockerClient(opts ...client.Opt) (*DockerClient, error) {
+	cli, err := client.NewClientWithOpts(append([]client.Opt{
 		client.FromEnv,
 		client.WithAPIVersionNegotiation(),
 		client.WithVersionFromEnv(),
-	)
+	}, opts...)...)
 	if err != nil {
 		return nil, fmt.Errorf("creating docker client: %w", err)
 	}
@@ -85,13 +113,75 @@ func NewDockerClient() (*DockerClient, error) {
 	}, nil
 }
 
+// AddLabels adds labels to attach to every object created with the client.
+// It must be called before the client is used.
+func (c *DockerClient) AddLabels(labels map[string]string) {
+	if c.labels == nil {
+		c.labels = make(map[string]string)
+	}
+	maps.Copy(c.labels, labels)
+}
+
+// Labels returns the labels to attach to the objects created with the client.
+func (c *DockerClient) Labels() map[string]string {
+	labels := maps.Clone(DefaultLabels)
+	maps.Copy(labels, c.labels)
+	return labels
+}
+
+// RemoveNetwork removes the network with the given name, if it exists.
+func (c *DockerClient) RemoveNetwork(ctx context.Context, name string) error {
+	network, err := c.NetworkInspect(ctx, name, types.NetworkInspectOptions{})
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("inspecting network %s: %w", name, err)
+	}
+
+	if err := c.NetworkRemove(ctx, network.ID); err != nil {
+		return fmt.Errorf("removing network %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// RemoveContainer force removes the container with the given name along with
+// its anonymous volumes, if it exists.
+func (c *DockerClient) RemoveContainer(ctx context.Context, name string) error {
+	if err := c.ContainerRemove(ctx, name, container.RemoveOptions{
+		RemoveVolumes: true,
+		Force:         true,
+	}); err != nil && !client.IsErrNotFound(err) {
+		return fmt.Errorf("removing container %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// RemoveVolume removes the volume with the given name, if it exists.
+func (c *DockerClient) RemoveVolume(ctx context.Context, name string, force bool) error {
+	if _, err := c.VolumeInspect(ctx, name); err != nil {
+		if client.IsErrNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("inspecting volume %s: %w", name, err)
+	}
+
+	if err := c.VolumeRemove(ctx, name, force); err != nil {
+		return fmt.Errorf("removing volume %s: %w", name, err)
+	}
+
+	return nil
+}
+
 // NewDocker creates a new DockerProvider with the given client.
 func NewDocker(name string, cli *DockerClient, req DockerRequest) *DockerProvider {
 	return &DockerProvider{
 		name:   name,
 		req:    req,
 		cli:    cli,
-		labels: DefaultLabels,
+		labels: cli.Labels(),
 	}
 }
 
@@ -151,7 +241,12 @@ func (p *DockerProvider) GetNetwork(ctx context.Context, name string) (string, e
 
 // Start implements Provider.
 func (p *DockerProvider) Start(ctx context.Context) error {
-	networkId, err := p.CreateNetwork(ctx, DockerDefaultNetworkName)
+	networkName := DockerDefaultNetworkName
+	if p.req.DefaultNetwork != "" {
+		networkName = p.req.DefaultNetwork
+	}
+
+	networkId, err := p.CreateNetwork(ctx, networkName)
 	if err != nil {
 		return fmt.Errorf("creating network: %w", err)
 	}
@@ -168,9 +263,14 @@ func (p *DockerProvider) Start(ctx context.Context) error {
 	}
 
 	hostConfig := &container.HostConfig{
-		NetworkMode: container.NetworkMode(networkId),
-		Mounts:      append(p.req.Mounts, p.req.ManagedVolumes...),
-		Privileged:  p.req.Privileged,
+		NetworkMode:    container.NetworkMode(networkId),
+		Mounts:         append(p.req.Mounts, p.req.ManagedVolumes...),
+		Privileged:     p.req.Privileged,
+		ReadonlyRootfs: p.req.ReadonlyRootfs,
+		Tmpfs:          p.req.Tmpfs,
+		CapAdd:         p.req.CapAdd,
+		CapDrop:        p.req.CapDrop,
+		SecurityOpt:    p.req.SecurityOpt,
 		RestartPolicy: container.RestartPolicy{
 			Name:              "on-failure",
 			MaximumRetryCount: 1,
@@ -221,6 +321,84 @@ func (p *DockerProvider) Start(ctx context.Context) error {
 	return nil
 }
 
+// Run creates a short lived container from the request, waits for it to exit
+// and removes it. The combined stdout and stderr of the container is returned,
+// and an error is returned if the container exits with a non-zero exit code.
+func (p *DockerProvider) Run(ctx context.Context) (io.Reader, error) {
+	config := &container.Config{
+		Image:      p.req.Ref.Name(),
+		User:       p.req.User,
+		Env:        p.req.Env.ToSlice(),
+		Entrypoint: p.req.Entrypoint,
+		Cmd:        p.req.Cmd,
+		Labels:     p.labels,
+	}
+
+	hostConfig := &container.HostConfig{
+		NetworkMode: container.NetworkMode(p.req.NetworkMode),
+		Mounts:      p.req.Mounts,
+		Privileged:  p.req.Privileged,
+		CapAdd:      p.req.CapAdd,
+		CapDrop:     p.req.CapDrop,
+		SecurityOpt: p.req.SecurityOpt,
+	}
+
+	if err := p.pull(ctx); err != nil {
+		return nil, fmt.Errorf("pulling image: %w", err)
+	}
+
+	resp, err := p.cli.ContainerCreate(ctx, config, hostConfig, nil, nil, p.name)
+	if err != nil {
+		return nil, fmt.Errorf("creating container: %w", err)
+	}
+	p.id = resp.ID
+
+	defer func() {
+		// always clean up, even when the parent context was cancelled
+		_ = p.cli.ContainerRemove(context.WithoutCancel(ctx), p.id, container.RemoveOptions{
+			Force: true,
+		})
+	}()
+
+	// wait for the next exit before starting to avoid missing a fast exit
+	waitCh, errCh := p.cli.ContainerWait(ctx, p.id, container.WaitConditionNextExit)
+
+	if err := p.cli.ContainerStart(ctx, p.id, container.StartOptions{}); err != nil {
+		return nil, fmt.Errorf("starting container: %w", err)
+	}
+
+	var exitCode int64
+	select {
+	case w := <-waitCh:
+		if w.Error != nil {
+			return nil, fmt.Errorf("waiting for container: %s", w.Error.Message)
+		}
+		exitCode = w.StatusCode
+	case err := <-errCh:
+		return nil, fmt.Errorf("waiting for container: %w", err)
+	}
+
+	logs, err := p.cli.ContainerLogs(ctx, p.id, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading container logs: %w", err)
+	}
+	defer logs.Close()
+
+	out := &bytes.Buffer{}
+	if _, err := stdcopy.StdCopy(out, out, logs); err != nil {
+		return nil, fmt.Errorf("reading container logs: %w", err)
+	}
+
+	if exitCode != 0 {
+		return nil, fmt.Errorf("container exited with non-zero exit code: %d\n\n%s", exitCode, out.String())
+	}
+
+	return out, nil
+}
+
 // Teardown implements Provider.
 func (p *DockerProvider) Teardown(ctx context.Context) error {
 	var errs []error
@@ -251,6 +429,13 @@ func (p *DockerProvider) Teardown(ctx context.Context) error {
 			continue
 		}
 
+		if p.req.VolumeRemover != nil {
+			if err := p.req.VolumeRemover(ctx, m.Source); err != nil {
+				errs = append(errs, fmt.Errorf("failed to remove volume: %w", err))
+			}
+			continue
+		}
+
 		volume, err := p.cli.VolumeInspect(ctx, m.Source)
 		if err == nil {
 			if err := p.cli.VolumeRemove(ctx, volume.Name, false); err != nil {
@@ -281,6 +466,8 @@ func (p *DockerProvider) Exec(ctx context.Context, config ExecConfig) (io.Reader
 	execConfig := types.ExecConfig{
 		Cmd:          append(base.DefaultEntrypoint(), config.Command),
 		WorkingDir:   config.WorkingDir,
+		User:         config.User,
+		Env:          config.Env,
 		AttachStderr: true,
 		AttachStdout: true,
 	}
@@ -346,8 +533,33 @@ func (p *DockerProvider) Exec(ctx context.Context, config ExecConfig) (io.Reader
 	return out, nil
 }
 
+// Stats returns a snapshot of the resources used by the running container.
+func (p *DockerProvider) Stats(ctx context.Context) (ContainerStats, error) {
+	resp, err := p.cli.ContainerStatsOneShot(ctx, p.id)
+	if err != nil {
+		return ContainerStats{}, fmt.Errorf("getting container stats: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var stats types.StatsJSON
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return ContainerStats{}, fmt.Errorf("decoding container stats: %w", err)
+	}
+
+	return ContainerStats{
+		MemoryUsage: int64(stats.MemoryStats.Usage),
+		CPUUsage:    time.Duration(stats.CPUStats.CPUUsage.TotalUsage),
+	}, nil
+}
+
 // pull the image if it doesn't exist in the daemon.
 func (p *DockerProvider) pull(ctx context.Context) error {
+	if p.req.PulledImages != nil {
+		if _, ok := p.req.PulledImages.Load(p.req.Ref.Name()); ok {
+			return nil
+		}
+	}
+
 	// check if the imageId exists in the daemon
 	_, _, err := p.cli.ImageInspectWithRaw(ctx, p.req.Ref.Name())
 	if err != nil {
@@ -387,6 +599,13 @@ func (p *DockerProvider) pull(ctx context.Context) error {
 		return err
 	}
 
-	_, err = io.ReadAll(pull)
-	return err
+	if _, err := io.ReadAll(pull); err != nil {
+		return err
+	}
+
+	if p.req.PulledImages != nil {
+		p.req.PulledImages.Store(p.req.Ref.Name(), struct{}{})
+	}
+
+	return nil
 }
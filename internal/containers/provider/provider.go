@@ -7,6 +7,7 @@ import (
 	"context"
 	"io"
 	"path/filepath"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -18,6 +19,13 @@ type ExecConfig struct {
 
 	// The working directory to be used to execute the command
 	WorkingDir string
+
+	// The user (uid[:gid]) to execute the command as. Defaults to the user the
+	// container was started with.
+	User string
+
+	// Env is the additional environment of the command, as KEY=VALUE pairs.
+	Env []string
 }
 
 type Provider interface {
@@ -39,6 +47,15 @@ type ContainerRequest struct {
 	Resources ContainerResourcesRequest
 }
 
+// ContainerStats is a point in time snapshot of the resources used by a
+// container.
+type ContainerStats struct {
+	// MemoryUsage is the current memory usage, in bytes
+	MemoryUsage int64
+	// CPUUsage is the total CPU time consumed since the container started
+	CPUUsage time.Duration
+}
+
 type ContainerResourcesRequest struct {
 	CpuRequest resource.Quantity
 	CpuLimit   resource.Quantity
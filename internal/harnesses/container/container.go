@@ -68,6 +68,11 @@ type Config struct {
 	ManagedVolumes []ConfigMount
 	Networks       []string
 	Privileged     bool
+	// DefaultNetwork is the network the container is created in, see
+	// provider.DockerRequest.
+	DefaultNetwork string
+	// VolumeRemover removes the managed volumes, see provider.DockerRequest.
+	VolumeRemover func(ctx context.Context, name string) error
 }
 
 // ConfigMount is a simplified wrapper around mount.Mount.
@@ -114,6 +119,8 @@ func New(name string, cli *provider.DockerClient, cfg Config) (types.Harness, er
 		},
 		Mounts:         mounts,
 		ManagedVolumes: managedVolumes,
+		DefaultNetwork: cfg.DefaultNetwork,
+		VolumeRemover:  cfg.VolumeRemover,
 	})
 
 	return &container{
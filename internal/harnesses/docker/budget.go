@@ -0,0 +1,140 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chainguard-dev/terraform-provider-imagetest/internal/containers/provider"
+	"github.com/chainguard-dev/terraform-provider-imagetest/internal/log"
+)
+
+// budgetPollInterval is how often the harness container resource usage is
+// checked against the budget while a step is running.
+const budgetPollInterval = time.Second
+
+// ResourceBudgetOpt is the total amount of resources the steps of a harness
+// may consume. Zero values are unlimited.
+type ResourceBudgetOpt struct {
+	// TotalMemory is the memory usage of the harness container, in bytes.
+	TotalMemory int64
+	// TotalCPU is the CPU time consumed by the harness container.
+	TotalCPU time.Duration
+	// TotalWallTime is the time elapsed since the harness was created.
+	TotalWallTime time.Duration
+}
+
+// StepIDEnv marks the processes of a step running under a budget, so they can
+// be found and killed once the budget is exceeded.
+const StepIDEnv = "IMAGETEST_STEP_ID"
+
+// budgetKillTimeout bounds killing the processes of a step that exceeded the
+// budget.
+const budgetKillTimeout = 30 * time.Second
+
+// ErrBudgetExceeded is returned by steps that were stopped because the harness
+// exceeded its resource budget.
+var ErrBudgetExceeded = errors.New("resource budget exceeded")
+
+// withBudget runs fn while tracking the harness resource usage, cancelling the
+// step as soon as any budget is exceeded. Cancelling only closes the exec
+// stream, so the processes of the step, marked with stepId, are then killed.
+// The container CPU usage is cumulative since the harness was started, so it
+// accounts for every step run so far.
+func (h *docker) withBudget(ctx context.Context, stepId string, fn func(context.Context) error) error {
+	if h.budget == nil {
+		return fn(ctx)
+	}
+
+	if err := h.checkBudget(ctx); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	if h.budget.TotalWallTime > 0 {
+		var cancelDeadline context.CancelFunc
+		ctx, cancelDeadline = context.WithDeadlineCause(ctx,
+			h.started.Add(h.budget.TotalWallTime),
+			fmt.Errorf("%w: total_wall_time of %s", ErrBudgetExceeded, h.budget.TotalWallTime))
+		defer cancelDeadline()
+	}
+
+	go func() {
+		ticker := time.NewTicker(budgetPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := h.checkBudget(ctx); errors.Is(err, ErrBudgetExceeded) {
+					cancel(err)
+					return
+				}
+			}
+		}
+	}()
+
+	err := fn(ctx)
+	if cause := context.Cause(ctx); errors.Is(cause, ErrBudgetExceeded) {
+		kctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), budgetKillTimeout)
+		defer cancel()
+
+		if err := h.killStep(kctx, stepId); err != nil {
+			log.Info(ctx, "failed to kill the step that exceeded the docker harness budget", "harness", h.id, "error", err)
+		}
+		return cause
+	}
+
+	return err
+}
+
+// killStep kills every process of the step marked with stepId. The marker is
+// in the environment of the step, which its child processes inherit.
+func (h *docker) killStep(ctx context.Context, stepId string) error {
+	script := fmt.Sprintf(`for p in /proc/[0-9]*; do
+  if tr '\0' '\n' < "$p/environ" 2>/dev/null | grep -qx '%s=%s'; then
+    kill -9 "${p#/proc/}" 2>/dev/null
+  fi
+done
+true`, StepIDEnv, stepId)
+
+	_, err := h.container.Exec(ctx, provider.ExecConfig{
+		Command: script,
+		User:    "0:0",
+	})
+	return err
+}
+
+// checkBudget returns an ErrBudgetExceeded error describing the breached
+// budget, if any.
+func (h *docker) checkBudget(ctx context.Context) error {
+	if h.budget.TotalWallTime > 0 && time.Since(h.started) > h.budget.TotalWallTime {
+		return fmt.Errorf("%w: total_wall_time of %s", ErrBudgetExceeded, h.budget.TotalWallTime)
+	}
+
+	if h.budget.TotalMemory == 0 && h.budget.TotalCPU == 0 {
+		return nil
+	}
+
+	stats, err := h.container.Stats(ctx)
+	if err != nil {
+		// don't fail the step just because the stats are unavailable
+		log.Info(ctx, "failed to get docker harness stats", "harness", h.id, "error", err)
+		return nil
+	}
+
+	if h.budget.TotalMemory > 0 && stats.MemoryUsage > h.budget.TotalMemory {
+		return fmt.Errorf("%w: total_memory of %d bytes (used %d bytes)", ErrBudgetExceeded, h.budget.TotalMemory, stats.MemoryUsage)
+	}
+
+	if h.budget.TotalCPU > 0 && stats.CPUUsage > h.budget.TotalCPU {
+		return fmt.Errorf("%w: total_cpu_seconds of %s (used %s)", ErrBudgetExceeded, h.budget.TotalCPU, stats.CPUUsage)
+	}
+
+	return nil
+}
@@ -7,6 +7,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sync"
+	"time"
 
 	"github.com/docker/docker/api/types/mount"
 
@@ -14,17 +16,47 @@ import (
 	"github.com/chainguard-dev/terraform-provider-imagetest/internal/harnesses/base"
 	"github.com/chainguard-dev/terraform-provider-imagetest/internal/log"
 	"github.com/chainguard-dev/terraform-provider-imagetest/internal/types"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/semaphore"
 )
 
 var _ types.Harness = &docker{}
 
-const DefaultDockerSocketPath = "/var/run/docker.sock"
+const (
+	DefaultDockerSocketPath = "/var/run/docker.sock"
+	// NonRootUser is the user steps are run as when running as root is not
+	// allowed. This is the conventional uid of "nobody".
+	NonRootUser = "65534"
+)
 
 type docker struct {
 	*base.Base
 	id string
 
-	container provider.Provider
+	cli       *provider.DockerClient
+	container *provider.DockerProvider
+	// stepUser is the user steps are executed as, empty to use the container
+	// user.
+	stepUser string
+	// egressFilter is applied to the harness container once it is started,
+	// nil when outbound traffic is not filtered.
+	egressFilter *EgressFilterOpt
+	// budget is the total resources the steps may consume, nil when
+	// unlimited.
+	budget *ResourceBudgetOpt
+	// started is when the harness container was started.
+	started time.Time
+	// steps limits the number of concurrently running steps, nil when
+	// unlimited.
+	steps *semaphore.Weighted
+	// pulledImages is shared with the containers the harness creates, nil
+	// when images are always pulled.
+	pulledImages *sync.Map
+	// span is the harness span when correlating with a parent trace, nil
+	// otherwise.
+	span trace.Span
 }
 
 type dockerAuthEntry struct {
@@ -37,6 +69,18 @@ type dockerConfig struct {
 	Auths map[string]dockerAuthEntry `json:"auths,omitempty"`
 }
 
+// ValidateOptions applies the options without creating anything, returning
+// the error of the first invalid option.
+func ValidateOptions(opts ...Option) error {
+	options := &HarnessDockerOptions{}
+	for _, opt := range opts {
+		if err := opt(options); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func New(id string, cli *provider.DockerClient, opts ...Option) (types.Harness, error) {
 	options := &HarnessDockerOptions{}
 	for _, opt := range opts {
@@ -85,6 +129,27 @@ func New(id string, cli *provider.DockerClient, opts ...Option) (types.Harness,
 		return nil, err
 	}
 
+	var span trace.Span
+	if options.TraceParent != "" {
+		var traceparent string
+		span, traceparent, err = startSpan(id, options.TraceParent)
+		if err != nil {
+			return nil, err
+		}
+
+		if options.Envs == nil {
+			options.Envs = make(provider.Env)
+		}
+		// steps are exec'd in the container, so they inherit its environment
+		options.Envs[TraceParentEnv] = traceparent
+	}
+
+	var tmpfs map[string]string
+	if options.ReadOnlyRootfs {
+		// steps still need somewhere to write temporary files
+		tmpfs = map[string]string{"/tmp": ""}
+	}
+
 	container := provider.NewDocker(id, cli, provider.DockerRequest{
 		ContainerRequest: provider.ContainerRequest{
 			Ref:        options.ImageRef,
@@ -103,26 +168,64 @@ func New(id string, cli *provider.DockerClient, opts ...Option) (types.Harness,
 		},
 		Mounts:         mounts,
 		ManagedVolumes: managedVolumes,
+		ReadonlyRootfs: options.ReadOnlyRootfs,
+		Tmpfs:          tmpfs,
+		CapAdd:         options.CapAdd,
+		CapDrop:        options.CapDrop,
+		SecurityOpt:    options.SecurityOpts,
+		PulledImages:   options.PulledImages,
+		DefaultNetwork: options.DefaultNetwork,
+		VolumeRemover:  options.VolumeRemover,
 	})
 
+	var stepUser string
+	if options.RunAsNonRoot {
+		stepUser = NonRootUser
+	}
+
+	var steps *semaphore.Weighted
+	if options.StepLimit > 0 {
+		steps = semaphore.NewWeighted(options.StepLimit)
+	}
+
 	return &docker{
-		Base:      base.New(),
-		id:        id,
-		container: container,
+		Base:         base.New(),
+		id:           id,
+		cli:          cli,
+		container:    container,
+		stepUser:     stepUser,
+		egressFilter: options.EgressFilter,
+		budget:       options.ResourceBudget,
+		steps:        steps,
+		pulledImages: options.PulledImages,
+		span:         span,
 	}, nil
 }
 
 func (h *docker) Setup() types.StepFn {
 	return h.WithCreate(func(ctx context.Context) (context.Context, error) {
 		if err := h.container.Start(ctx); err != nil {
+			h.failSpan(err)
 			return ctx, fmt.Errorf("failed starting docker service: %w", err)
 		}
+		h.started = time.Now()
+
+		if h.egressFilter != nil {
+			if err := h.applyEgressFilter(ctx); err != nil {
+				h.failSpan(err)
+				return ctx, fmt.Errorf("failed applying egress filter: %w", err)
+			}
+		}
 
 		return ctx, nil
 	})
 }
 
 func (h *docker) Destroy(ctx context.Context) error {
+	if h.span != nil {
+		defer h.span.End()
+	}
+
 	if err := h.container.Teardown(ctx); err != nil {
 		return fmt.Errorf("tearing down sandbox: %w", err)
 	}
@@ -132,24 +235,51 @@ func (h *docker) Destroy(ctx context.Context) error {
 
 func (h *docker) StepFn(config types.StepConfig) types.StepFn {
 	return func(ctx context.Context) (context.Context, error) {
-		log.Info(ctx, "stepping in docker container", "command", config.Command)
-		r, err := h.container.Exec(ctx, provider.ExecConfig{
-			Command:    config.Command,
-			WorkingDir: config.WorkingDir,
-		})
-		if err != nil {
-			return ctx, err
+		if h.steps != nil {
+			if err := h.steps.Acquire(ctx, 1); err != nil {
+				return ctx, fmt.Errorf("waiting for a free step slot: %w", err)
+			}
+			defer h.steps.Release(1)
 		}
 
-		out, err := io.ReadAll(r)
+		log.Info(ctx, "stepping in docker container", "command", config.Command)
+
+		stepId := uuid.NewString()
+
+		var out []byte
+		err := h.withBudget(ctx, stepId, func(ctx context.Context) error {
+			r, err := h.container.Exec(ctx, provider.ExecConfig{
+				Command:    config.Command,
+				WorkingDir: config.WorkingDir,
+				User:       h.stepUser,
+				Env:        []string{StepIDEnv + "=" + stepId},
+			})
+			if err != nil {
+				return err
+			}
+
+			out, err = io.ReadAll(r)
+			return err
+		})
 		if err != nil {
 			return ctx, err
 		}
 
 		log.Info(ctx, "finished stepping in docker container", "command", config.Command, "out", string(out))
 
-		return ctx, nil
+		return types.WithStepOutput(ctx, string(out)), nil
+	}
+}
+
+// failSpan marks the harness span as failed and ends it, since a harness that
+// failed its setup is torn down without being destroyed.
+func (h *docker) failSpan(err error) {
+	if h.span == nil {
+		return
 	}
+	h.span.RecordError(err)
+	h.span.SetStatus(codes.Error, err.Error())
+	h.span.End()
 }
 
 // createDockerConfigJSON creates a Docker config.json file used by the harness for auth.
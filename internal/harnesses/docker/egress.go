@@ -0,0 +1,122 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/chainguard-dev/terraform-provider-imagetest/internal/containers/provider"
+	"github.com/chainguard-dev/terraform-provider-imagetest/internal/harnesses/base"
+	"github.com/chainguard-dev/terraform-provider-imagetest/internal/log"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// EgressFilterImage is the image used to install the egress filter rules into
+// the network namespace of the harness container.
+const EgressFilterImage = "cgr.dev/chainguard/wolfi-base:latest"
+
+type EgressFilterOpt struct {
+	AllowedDomains []string
+	AllowedIPs     []string
+}
+
+// applyEgressFilter runs a short lived setup container in the network
+// namespace of the harness container that drops all outbound traffic to
+// destinations that are not explicitly allowed.
+func (h *docker) applyEgressFilter(ctx context.Context) error {
+	script, err := egressFilterScript(ctx, h.egressFilter)
+	if err != nil {
+		return err
+	}
+
+	ref, err := name.ParseReference(EgressFilterImage)
+	if err != nil {
+		return fmt.Errorf("invalid egress filter image reference: %w", err)
+	}
+
+	setup := provider.NewDocker(h.id+"-egress-filter", h.cli, provider.DockerRequest{
+		ContainerRequest: provider.ContainerRequest{
+			Ref:        ref,
+			Entrypoint: base.DefaultEntrypoint(),
+			Cmd:        []string{script},
+			User:       "0:0",
+		},
+		NetworkMode:  "container:" + h.id,
+		CapAdd:       []string{"NET_ADMIN", "NET_RAW"},
+		PulledImages: h.pulledImages,
+	})
+
+	r, err := setup.Run(ctx)
+	if err != nil {
+		return fmt.Errorf("running egress filter setup container: %w", err)
+	}
+
+	out, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	log.Info(ctx, "applied egress filter to docker harness", "harness", h.id, "out", string(out))
+
+	return nil
+}
+
+// egressFilterScript returns the shell script that installs the egress filter
+// rules. Allowed domains are resolved on the host when the script is created,
+// so only the addresses they resolve to at that time are allowed.
+func egressFilterScript(ctx context.Context, opt *EgressFilterOpt) (string, error) {
+	allowed := make([]string, 0, len(opt.AllowedIPs))
+	allowed = append(allowed, opt.AllowedIPs...)
+
+	for _, domain := range opt.AllowedDomains {
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, domain)
+		if err != nil {
+			return "", fmt.Errorf("resolving allowed domain %s: %w", domain, err)
+		}
+
+		for _, addr := range addrs {
+			if v4 := addr.IP.To4(); v4 != nil {
+				allowed = append(allowed, v4.String()+"/32")
+			} else {
+				allowed = append(allowed, addr.IP.String()+"/128")
+			}
+		}
+	}
+
+	// always allow loopback traffic (including the embedded DNS server) and
+	// replies to established connections
+	v4 := []string{
+		"iptables -A OUTPUT -o lo -j ACCEPT",
+		"iptables -A OUTPUT -m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT",
+	}
+	v6 := []string{
+		"ip6tables -A OUTPUT -o lo -j ACCEPT",
+		"ip6tables -A OUTPUT -m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT",
+	}
+
+	for _, cidr := range allowed {
+		if strings.Contains(cidr, ":") {
+			v6 = append(v6, fmt.Sprintf("ip6tables -A OUTPUT -d %s -j ACCEPT", cidr))
+		} else {
+			v4 = append(v4, fmt.Sprintf("iptables -A OUTPUT -d %s -j ACCEPT", cidr))
+		}
+	}
+
+	v4 = append(v4, "iptables -A OUTPUT -j DROP")
+	v6 = append(v6, "ip6tables -A OUTPUT -j DROP")
+
+	lines := []string{
+		"set -e",
+		"apk add --no-cache iptables >/dev/null",
+	}
+	lines = append(lines, v4...)
+	// ip6tables is unusable when IPv6 is disabled in the namespace, in which
+	// case there is no IPv6 traffic to filter
+	lines = append(lines, "if ip6tables -L OUTPUT >/dev/null 2>&1; then")
+	lines = append(lines, v6...)
+	lines = append(lines, "fi")
+
+	return strings.Join(lines, "\n"), nil
+}
@@ -1,7 +1,13 @@
 package docker
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
 
 	"github.com/chainguard-dev/terraform-provider-imagetest/internal/containers/provider"
 	"github.com/chainguard-dev/terraform-provider-imagetest/internal/harnesses/base"
@@ -19,6 +25,18 @@ type HarnessDockerOptions struct {
 	Envs             provider.Env
 	Registries       map[string]*RegistryOpt
 	ConfigVolumeName string
+	RunAsNonRoot     bool
+	ReadOnlyRootfs   bool
+	CapAdd           []string
+	CapDrop          []string
+	SecurityOpts     []string
+	EgressFilter     *EgressFilterOpt
+	ResourceBudget   *ResourceBudgetOpt
+	StepLimit        int64
+	PulledImages     *sync.Map
+	TraceParent      string
+	DefaultNetwork   string
+	VolumeRemover    func(ctx context.Context, name string) error
 }
 
 type RegistryOpt struct {
@@ -60,6 +78,24 @@ func WithNetworks(networks ...string) Option {
 	}
 }
 
+// WithDefaultNetwork sets the network the harness container is created in, see
+// provider.DockerRequest.
+func WithDefaultNetwork(name string) Option {
+	return func(opt *HarnessDockerOptions) error {
+		opt.DefaultNetwork = name
+		return nil
+	}
+}
+
+// WithVolumeRemover sets the func called to remove the managed volumes, see
+// provider.DockerRequest.
+func WithVolumeRemover(remover func(ctx context.Context, name string) error) Option {
+	return func(opt *HarnessDockerOptions) error {
+		opt.VolumeRemover = remover
+		return nil
+	}
+}
+
 func WithAuthFromStatic(registry, username, password, auth string) Option {
 	return func(opt *HarnessDockerOptions) error {
 		if opt.Registries == nil {
@@ -146,3 +182,138 @@ func WithConfigVolumeName(configVolumeName string) Option {
 		return nil
 	}
 }
+
+func WithRunAsNonRoot(runAsNonRoot bool) Option {
+	return func(opt *HarnessDockerOptions) error {
+		opt.RunAsNonRoot = runAsNonRoot
+		return nil
+	}
+}
+
+func WithReadOnlyRootfs(readOnly bool) Option {
+	return func(opt *HarnessDockerOptions) error {
+		opt.ReadOnlyRootfs = readOnly
+		return nil
+	}
+}
+
+// WithCapabilities adds the given capabilities to the harness container. When
+// dropAll is true, every other capability is dropped.
+func WithCapabilities(dropAll bool, capAdd ...string) Option {
+	return func(opt *HarnessDockerOptions) error {
+		if dropAll {
+			opt.CapDrop = []string{"ALL"}
+		}
+		opt.CapAdd = append(opt.CapAdd, capAdd...)
+		return nil
+	}
+}
+
+// WithSeccompProfile applies the given seccomp profile to the harness
+// container. The profile is either "default" to use the daemon's default
+// profile, "unconfined", or a path to a seccomp JSON profile.
+func WithSeccompProfile(profile string) Option {
+	return func(opt *HarnessDockerOptions) error {
+		switch profile {
+		case "", "default":
+			// the daemon applies its default profile when none is given
+			return nil
+		case "unconfined":
+			opt.SecurityOpts = append(opt.SecurityOpts, "seccomp=unconfined")
+			return nil
+		}
+
+		data, err := os.ReadFile(profile)
+		if err != nil {
+			return fmt.Errorf("reading seccomp profile: %w", err)
+		}
+
+		if !json.Valid(data) {
+			return fmt.Errorf("seccomp profile %s is not valid JSON", profile)
+		}
+
+		// unlike the docker cli, the api expects the profile contents rather
+		// than the path
+		opt.SecurityOpts = append(opt.SecurityOpts, "seccomp="+string(data))
+		return nil
+	}
+}
+
+// WithAppArmorProfile applies the given AppArmor profile to the harness
+// container. The profile is either "runtime/default", "unconfined", or the name
+// of a profile loaded on the host.
+func WithAppArmorProfile(profile string) Option {
+	return func(opt *HarnessDockerOptions) error {
+		if strings.TrimSpace(profile) == "" {
+			return fmt.Errorf("apparmor profile must not be empty")
+		}
+
+		if profile == "runtime/default" {
+			// runtime/default is the CRI name of the runtime's default
+			// profile, which the daemon applies when none is given
+			return nil
+		}
+
+		opt.SecurityOpts = append(opt.SecurityOpts, "apparmor="+profile)
+		return nil
+	}
+}
+
+// WithEgressFilter drops all outbound traffic from the harness container that
+// is not destined to one of the allowed domains or CIDR ranges.
+func WithEgressFilter(allowedDomains, allowedIPs []string) Option {
+	return func(opt *HarnessDockerOptions) error {
+		for _, cidr := range allowedIPs {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return fmt.Errorf("invalid allowed CIDR range %s: %w", cidr, err)
+			}
+		}
+
+		opt.EgressFilter = &EgressFilterOpt{
+			AllowedDomains: allowedDomains,
+			AllowedIPs:     allowedIPs,
+		}
+		return nil
+	}
+}
+
+func WithResourceBudget(budget ResourceBudgetOpt) Option {
+	return func(opt *HarnessDockerOptions) error {
+		opt.ResourceBudget = &budget
+		return nil
+	}
+}
+
+// WithConcurrentStepLimit limits how many steps may run in the harness at the
+// same time. Steps of different features using the same harness run
+// concurrently, a limit of 0 means unlimited.
+func WithConcurrentStepLimit(limit int64) Option {
+	return func(opt *HarnessDockerOptions) error {
+		if limit < 0 {
+			return fmt.Errorf("concurrent step limit must not be negative, got %d", limit)
+		}
+		opt.StepLimit = limit
+		return nil
+	}
+}
+
+// WithImageCache skips pulling images that were already pulled by another
+// user of the same cache.
+func WithImageCache(pulled *sync.Map) Option {
+	return func(opt *HarnessDockerOptions) error {
+		opt.PulledImages = pulled
+		return nil
+	}
+}
+
+// WithTraceParent correlates the harness with a distributed trace, given as a
+// W3C traceparent.
+func WithTraceParent(traceparent string) Option {
+	return func(opt *HarnessDockerOptions) error {
+		if _, err := extractTraceParent(traceparent); err != nil {
+			return err
+		}
+		opt.TraceParent = traceparent
+		return nil
+	}
+}
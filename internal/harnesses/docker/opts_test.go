@@ -0,0 +1,44 @@
+package docker
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWithAppArmorProfile(t *testing.T) {
+	testCases := map[string]struct {
+		profile string
+		want    []string
+		wantErr bool
+	}{
+		"runtime default": {
+			profile: "runtime/default",
+			want:    nil,
+		},
+		"unconfined": {
+			profile: "unconfined",
+			want:    []string{"apparmor=unconfined"},
+		},
+		"host profile": {
+			profile: "imagetest-profile",
+			want:    []string{"apparmor=imagetest-profile"},
+		},
+		"empty": {
+			profile: " ",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			opt := &HarnessDockerOptions{}
+			err := WithAppArmorProfile(tc.profile)(opt)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("WithAppArmorProfile(%q) error = %v, wantErr %v", tc.profile, err, tc.wantErr)
+			}
+			if !slices.Equal(opt.SecurityOpts, tc.want) {
+				t.Errorf("WithAppArmorProfile(%q) security opts = %v, want %v", tc.profile, opt.SecurityOpts, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceParentEnv is the environment variable the harness span context is
+// propagated to steps with.
+const TraceParentEnv = "TRACEPARENT"
+
+// tracerName is the instrumentation name of the harness spans, created with
+// the global tracer provider.
+const tracerName = "github.com/chainguard-dev/terraform-provider-imagetest/internal/harnesses/docker"
+
+var traceContext = propagation.TraceContext{}
+
+// extractTraceParent returns a context carrying the span context of a W3C
+// traceparent header.
+func extractTraceParent(traceparent string) (context.Context, error) {
+	ctx := traceContext.Extract(context.Background(), propagation.MapCarrier{"traceparent": traceparent})
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return nil, fmt.Errorf("invalid W3C traceparent %q", traceparent)
+	}
+	return ctx, nil
+}
+
+// startSpan starts the harness span as a child of traceparent and returns it
+// along with its own traceparent.
+func startSpan(id string, traceparent string) (trace.Span, string, error) {
+	ctx, err := extractTraceParent(traceparent)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "imagetest_harness_docker",
+		trace.WithAttributes(attribute.String("imagetest.harness.id", id)))
+
+	carrier := propagation.MapCarrier{}
+	traceContext.Inject(ctx, carrier)
+
+	return span, carrier.Get("traceparent"), nil
+}
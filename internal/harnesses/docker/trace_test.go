@@ -0,0 +1,64 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStartSpan(t *testing.T) {
+	const parent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	recorder := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	span, traceparent, err := startSpan("harness", parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	span.End()
+
+	if !strings.HasPrefix(traceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-") || traceparent == parent {
+		t.Errorf("traceparent = %s, want a child of %s", traceparent, parent)
+	}
+
+	ended := recorder.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("exported %d spans, want 1", len(ended))
+	}
+	if got := ended[0].Parent().SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("span parent = %s, want 00f067aa0ba902b7", got)
+	}
+}
+
+func TestStartSpanInvalidTraceParent(t *testing.T) {
+	if _, _, err := startSpan("harness", "not-a-traceparent"); err == nil {
+		t.Error("startSpan() with an invalid traceparent succeeded")
+	}
+}
+
+func TestFailSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	_, span := tp.Tracer(tracerName).Start(context.Background(), "harness")
+	h := &docker{span: span}
+	h.failSpan(errors.New("setup failed"))
+
+	// the harness is torn down without being destroyed after a failed setup
+	ended := recorder.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("ended %d spans, want 1", len(ended))
+	}
+	if got := ended[0].Status().Code; got != codes.Error {
+		t.Errorf("span status = %v, want %v", got, codes.Error)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sync"
 )
 
@@ -19,8 +20,14 @@ func NewFile(path string) Inventory {
 	return &file{path: path}
 }
 
-// Create implements Inventory.
+// Create implements Inventory. An existing inventory at the same path is
+// reset, so a run never picks up the harnesses and features of a previous run
+// sharing its seed.
 func (i *file) Create(ctx context.Context) error {
+	if err := os.MkdirAll(filepath.Dir(i.path), 0755); err != nil {
+		return fmt.Errorf("creating inventory directory: %w", err)
+	}
+
 	data := InventoryModel{}
 	return i.write(ctx, data)
 }
@@ -121,6 +128,21 @@ func (i *file) RemoveFeature(ctx context.Context, f Feature) ([]Feature, error)
 	return features, nil
 }
 
+// GetHarnesses implements Inventory.
+func (i *file) GetHarnesses(ctx context.Context) ([]Harness, error) {
+	data, err := i.read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory: %w", err)
+	}
+
+	harnesses := make([]Harness, 0, len(data))
+	for h := range data {
+		harnesses = append(harnesses, h)
+	}
+
+	return harnesses, nil
+}
+
 // RemoveHarness implements Inventory.
 func (i *file) RemoveHarness(ctx context.Context, h Harness) error {
 	data, err := i.read(ctx)
@@ -163,7 +185,7 @@ func (i *file) write(_ context.Context, data InventoryModel) error {
 	i.mu.Lock()
 	defer i.mu.Unlock()
 
-	f, err := os.OpenFile(i.path, os.O_WRONLY|os.O_CREATE, 0644)
+	f, err := os.OpenFile(i.path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
 	if err != nil {
 		return fmt.Errorf("inventory open error")
 	}
@@ -0,0 +1,56 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileCreateResets(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "inventory")
+
+	prev := NewFile(path)
+	if err := prev.Create(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := prev.AddHarness(ctx, "previous-run-harness"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := prev.AddFeature(ctx, Feature{Id: "previous-run-feature", Harness: "previous-run-harness"}); err != nil {
+		t.Fatal(err)
+	}
+
+	inv := NewFile(path)
+	if err := inv.Create(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// the previous inventory is longer, none of it must be left behind
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var model InventoryModel
+	if err := json.Unmarshal(raw, &model); err != nil {
+		t.Fatalf("inventory file %q is not valid: %v", raw, err)
+	}
+
+	harnesses, err := inv.GetHarnesses(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(harnesses) != 0 {
+		t.Errorf("GetHarnesses() = %v, want none after Create", harnesses)
+	}
+
+	added, err := inv.AddHarness(ctx, "previous-run-harness")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !added {
+		t.Error("AddHarness() reused the harness of the previous run")
+	}
+}
@@ -10,6 +10,7 @@ type Inventory interface {
 	AddHarness(ctx context.Context, id Harness) (bool, error)
 	AddFeature(ctx context.Context, f Feature) (bool, error)
 	GetFeatures(ctx context.Context, id Harness) ([]Feature, error)
+	GetHarnesses(ctx context.Context) ([]Harness, error)
 	RemoveHarness(ctx context.Context, h Harness) error
 	RemoveFeature(ctx context.Context, f Feature) ([]Feature, error)
 }
@@ -0,0 +1,200 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var _ Notifier = &Allure{}
+
+// Allure uploads the harness steps to Allure TestOps as a launch, one Allure
+// test result per step.
+type Allure struct {
+	URL       string
+	ProjectID int64
+	// Token is an Allure TestOps API token, exchanged for an access token
+	// before uploading.
+	Token string
+}
+
+type allureResult struct {
+	UUID          string             `json:"uuid"`
+	HistoryID     string             `json:"historyId"`
+	Name          string             `json:"name"`
+	FullName      string             `json:"fullName"`
+	Status        string             `json:"status"`
+	StatusDetails *allureDetails     `json:"statusDetails,omitempty"`
+	Stage         string             `json:"stage"`
+	Start         int64              `json:"start"`
+	Stop          int64              `json:"stop"`
+	Labels        []allureLabel      `json:"labels"`
+	Attachments   []allureAttachment `json:"attachments,omitempty"`
+}
+
+type allureDetails struct {
+	Message string `json:"message"`
+}
+
+type allureLabel struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type allureAttachment struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Type   string `json:"type"`
+}
+
+type allureLaunch struct {
+	Name      string `json:"name"`
+	ProjectID int64  `json:"projectId"`
+}
+
+// Started implements Notifier.
+func (a *Allure) Started(context.Context, string) error {
+	return nil
+}
+
+// Finished implements Notifier.
+func (a *Allure) Finished(ctx context.Context, result Result) error {
+	files, err := allureResults(result)
+	if err != nil {
+		return err
+	}
+
+	token, err := a.accessToken(ctx)
+	if err != nil {
+		return fmt.Errorf("authenticating to allure: %w", err)
+	}
+	headers := map[string]string{"Authorization": "Bearer " + token}
+
+	var launch struct {
+		ID int64 `json:"id"`
+	}
+	if err := doJSON(ctx, http.MethodPost, a.baseURL()+"/api/rs/launch", headers, allureLaunch{
+		Name:      fmt.Sprintf("imagetest harness %s", result.Harness),
+		ProjectID: a.ProjectID,
+	}, &launch); err != nil {
+		return fmt.Errorf("creating allure launch: %w", err)
+	}
+
+	if err := a.upload(ctx, token, launch.ID, files); err != nil {
+		return fmt.Errorf("uploading allure results: %w", err)
+	}
+
+	if err := doJSON(ctx, http.MethodPost, fmt.Sprintf("%s/api/rs/launch/%d/close", a.baseURL(), launch.ID), headers, nil, nil); err != nil {
+		return fmt.Errorf("closing allure launch: %w", err)
+	}
+
+	return nil
+}
+
+// allureResults converts the steps to the Allure results format, keyed by file
+// name. The output of each step is attached as a text file.
+func allureResults(result Result) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+
+	for _, s := range result.Steps {
+		id := uuid.NewString()
+		r := allureResult{
+			UUID:      id,
+			HistoryID: fmt.Sprintf("%s/%s/%s", result.Harness, s.Feature, s.Step),
+			Name:      s.Step,
+			FullName:  fmt.Sprintf("%s: %s", s.Feature, s.Step),
+			Status:    "passed",
+			Stage:     "finished",
+			Start:     s.Start.UnixMilli(),
+			Stop:      s.Start.Add(s.Duration).UnixMilli(),
+			Labels: []allureLabel{
+				{Name: "parentSuite", Value: result.Harness},
+				{Name: "suite", Value: s.Feature},
+				{Name: "framework", Value: "imagetest"},
+			},
+		}
+		if s.Err != nil {
+			r.Status = "failed"
+			r.StatusDetails = &allureDetails{Message: s.Err.Error()}
+		}
+
+		if s.Output != "" {
+			source := id + "-attachment.txt"
+			files[source] = []byte(s.Output)
+			r.Attachments = append(r.Attachments, allureAttachment{
+				Name:   "stdout/stderr",
+				Source: source,
+				Type:   "text/plain",
+			})
+		}
+
+		data, err := json.Marshal(r)
+		if err != nil {
+			return nil, fmt.Errorf("encoding allure result: %w", err)
+		}
+		files[id+"-result.json"] = data
+	}
+
+	return files, nil
+}
+
+// accessToken exchanges the API token for an access token.
+func (a *Allure) accessToken(ctx context.Context) (string, error) {
+	form := url.Values{
+		"grant_type": {"apitoken"},
+		"scope":      {"openid"},
+		"token":      {a.Token},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL()+"/api/uaa/oauth/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := send(req, &token); err != nil {
+		return "", err
+	}
+
+	return token.AccessToken, nil
+}
+
+func (a *Allure) upload(ctx context.Context, token string, launch int64, files map[string][]byte) error {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	for name, data := range files {
+		part, err := w.CreateFormFile("file", name)
+		if err != nil {
+			return err
+		}
+		if _, err := part.Write(data); err != nil {
+			return err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/api/rs/launch/%d/upload/file", a.baseURL(), launch), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return send(req, nil)
+}
+
+func (a *Allure) baseURL() string {
+	return strings.TrimSuffix(a.URL, "/")
+}
@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAllureResults(t *testing.T) {
+	start := time.UnixMilli(1700000000000)
+
+	testCases := map[string]struct {
+		step            StepResult
+		wantStatus      string
+		wantAttachments int
+	}{
+		"passed": {
+			step:       StepResult{Feature: "f", Step: "s", Start: start, Duration: time.Second},
+			wantStatus: "passed",
+		},
+		"failed": {
+			step:       StepResult{Feature: "f", Step: "s", Start: start, Duration: time.Second, Err: errors.New("boom")},
+			wantStatus: "failed",
+		},
+		"with output": {
+			step:            StepResult{Feature: "f", Step: "s", Start: start, Duration: time.Second, Output: "hello"},
+			wantStatus:      "passed",
+			wantAttachments: 1,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			files, err := allureResults(Result{Harness: "docker", Steps: []StepResult{tc.step}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(files) != 1+tc.wantAttachments {
+				t.Fatalf("got %d files, want %d", len(files), 1+tc.wantAttachments)
+			}
+
+			var r allureResult
+			for name, data := range files {
+				if !strings.HasSuffix(name, "-result.json") {
+					continue
+				}
+				if err := json.Unmarshal(data, &r); err != nil {
+					t.Fatalf("decoding %s: %v", name, err)
+				}
+			}
+
+			if r.Status != tc.wantStatus {
+				t.Errorf("status = %s, want %s", r.Status, tc.wantStatus)
+			}
+			if (r.StatusDetails != nil) != (tc.step.Err != nil) {
+				t.Errorf("status details = %+v, want set only on failure", r.StatusDetails)
+			}
+			if r.HistoryID != "docker/f/s" {
+				t.Errorf("history id = %s, want docker/f/s", r.HistoryID)
+			}
+			if r.Stop-r.Start != 1000 {
+				t.Errorf("stop - start = %d, want 1000", r.Stop-r.Start)
+			}
+			if len(r.Attachments) != tc.wantAttachments {
+				t.Fatalf("got %d attachments, want %d", len(r.Attachments), tc.wantAttachments)
+			}
+			for _, a := range r.Attachments {
+				if string(files[a.Source]) != tc.step.Output {
+					t.Errorf("attachment %s = %q, want %q", a.Source, files[a.Source], tc.step.Output)
+				}
+			}
+		})
+	}
+}
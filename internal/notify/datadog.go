@@ -0,0 +1,115 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// DefaultDatadogSite is the Datadog site used when DD_SITE is not set.
+const DefaultDatadogSite = "datadoghq.com"
+
+// Datadog metric types, as defined by the v2 series API.
+const (
+	datadogCount = 1
+	datadogGauge = 3
+)
+
+var _ Notifier = &Datadog{}
+
+// Datadog ships the harness run metrics to Datadog.
+type Datadog struct {
+	APIKey string
+	AppKey string
+	Tags   []string
+
+	// url overrides the series API endpoint derived from DD_SITE.
+	url string
+}
+
+type datadogSeries struct {
+	Series []datadogMetric `json:"series"`
+}
+
+type datadogMetric struct {
+	Metric string         `json:"metric"`
+	Type   int            `json:"type"`
+	Unit   string         `json:"unit,omitempty"`
+	Points []datadogPoint `json:"points"`
+	Tags   []string       `json:"tags,omitempty"`
+}
+
+type datadogPoint struct {
+	Timestamp int64   `json:"timestamp"`
+	Value     float64 `json:"value"`
+}
+
+// Started implements Notifier.
+func (d *Datadog) Started(context.Context, string) error {
+	return nil
+}
+
+// Finished implements Notifier.
+func (d *Datadog) Finished(ctx context.Context, result Result) error {
+	now := time.Now().Unix()
+	tags := append([]string{"harness:" + result.Harness}, d.Tags...)
+
+	metric := func(name string, typ int, unit string, value float64, extra ...string) datadogMetric {
+		return datadogMetric{
+			Metric: name,
+			Type:   typ,
+			Unit:   unit,
+			Points: []datadogPoint{{Timestamp: now, Value: value}},
+			Tags:   append(append([]string(nil), tags...), extra...),
+		}
+	}
+
+	status := "passed"
+	if result.Failed() {
+		status = "failed"
+	}
+
+	var passed, failed float64
+	series := datadogSeries{}
+	for _, s := range result.Steps {
+		stepStatus := "passed"
+		if s.Err != nil {
+			stepStatus = "failed"
+			failed++
+		} else {
+			passed++
+		}
+		series.Series = append(series.Series, metric("imagetest.step.duration", datadogGauge, "second", s.Duration.Seconds(),
+			"feature:"+s.Feature, "step:"+s.Step, "status:"+stepStatus))
+	}
+
+	series.Series = append(series.Series,
+		metric("imagetest.steps.passed", datadogCount, "", passed),
+		metric("imagetest.steps.failed", datadogCount, "", failed),
+		metric("imagetest.harness.duration", datadogGauge, "second", result.Duration.Seconds(), "status:"+status),
+	)
+
+	headers := map[string]string{"DD-API-KEY": d.APIKey}
+	if d.AppKey != "" {
+		headers["DD-APPLICATION-KEY"] = d.AppKey
+	}
+
+	if err := postJSON(ctx, d.seriesURL(), headers, series); err != nil {
+		return fmt.Errorf("shipping datadog metrics: %w", err)
+	}
+
+	return nil
+}
+
+func (d *Datadog) seriesURL() string {
+	if d.url != "" {
+		return d.url
+	}
+
+	site := os.Getenv("DD_SITE")
+	if site == "" {
+		site = DefaultDatadogSite
+	}
+	return fmt.Sprintf("https://api.%s/api/v2/series", site)
+}
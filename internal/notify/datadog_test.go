@@ -0,0 +1,108 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDatadogFinished(t *testing.T) {
+	var got datadogSeries
+	var headers http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding series: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	d := &Datadog{APIKey: "api-key", AppKey: "app-key", Tags: []string{"env:ci"}, url: srv.URL}
+	err := d.Finished(context.Background(), Result{
+		Harness:  "docker",
+		Duration: 10 * time.Second,
+		Steps: []StepResult{
+			{Feature: "f", Step: "ok", Duration: 2 * time.Second},
+			{Feature: "f", Step: "ko", Duration: time.Second, Err: errors.New("boom")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := headers.Get("DD-API-KEY"); v != "api-key" {
+		t.Errorf("DD-API-KEY = %q, want api-key", v)
+	}
+	if v := headers.Get("DD-APPLICATION-KEY"); v != "app-key" {
+		t.Errorf("DD-APPLICATION-KEY = %q, want app-key", v)
+	}
+
+	want := map[string]struct {
+		typ   int
+		value float64
+		tags  []string
+	}{
+		"imagetest.steps.passed":     {datadogCount, 1, []string{"harness:docker", "env:ci"}},
+		"imagetest.steps.failed":     {datadogCount, 1, []string{"harness:docker", "env:ci"}},
+		"imagetest.harness.duration": {datadogGauge, 10, []string{"harness:docker", "env:ci", "status:failed"}},
+	}
+	steps := 0
+	for _, m := range got.Series {
+		if len(m.Points) != 1 {
+			t.Errorf("%s has %d points, want 1", m.Metric, len(m.Points))
+			continue
+		}
+		if m.Metric == "imagetest.step.duration" {
+			steps++
+			if m.Type != datadogGauge || !slices.Contains(m.Tags, "feature:f") {
+				t.Errorf("unexpected step metric %+v", m)
+			}
+			continue
+		}
+		w, ok := want[m.Metric]
+		if !ok {
+			t.Errorf("unexpected metric %s", m.Metric)
+			continue
+		}
+		delete(want, m.Metric)
+		if m.Type != w.typ || m.Points[0].Value != w.value || !slices.Equal(m.Tags, w.tags) {
+			t.Errorf("%s = %+v, want type %d value %v tags %v", m.Metric, m, w.typ, w.value, w.tags)
+		}
+	}
+	if steps != 2 {
+		t.Errorf("got %d step metrics, want 2", steps)
+	}
+	for name := range want {
+		t.Errorf("missing metric %s", name)
+	}
+}
+
+func TestDatadogSeriesURL(t *testing.T) {
+	testCases := map[string]struct {
+		site string
+		want string
+	}{
+		"default site": {
+			want: "https://api.datadoghq.com/api/v2/series",
+		},
+		"eu site": {
+			site: "datadoghq.eu",
+			want: "https://api.datadoghq.eu/api/v2/series",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("DD_SITE", tc.site)
+			if got := (&Datadog{}).seriesURL(); got != tc.want {
+				t.Errorf("seriesURL() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
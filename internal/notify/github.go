@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultGitHubAPIURL is the GitHub API used when GITHUB_API_URL is not set.
+const DefaultGitHubAPIURL = "https://api.github.com"
+
+var _ Notifier = &GitHubStatus{}
+
+// GitHubStatus reports the harness run as a GitHub commit status.
+type GitHubStatus struct {
+	Token string
+	// Repo is the repository in the owner/name form.
+	Repo    string
+	Sha     string
+	Context string
+}
+
+type gitHubStatus struct {
+	State       string `json:"state"`
+	TargetURL   string `json:"target_url,omitempty"`
+	Description string `json:"description,omitempty"`
+	Context     string `json:"context"`
+}
+
+// Started implements Notifier.
+func (g *GitHubStatus) Started(ctx context.Context, harness string) error {
+	return g.update(ctx, gitHubStatus{
+		State:       "pending",
+		TargetURL:   RunURL(),
+		Description: fmt.Sprintf("Running imagetest harness %s", harness),
+	})
+}
+
+// Finished implements Notifier.
+func (g *GitHubStatus) Finished(ctx context.Context, result Result) error {
+	status := gitHubStatus{
+		State:       "success",
+		TargetURL:   result.RunURL,
+		Description: fmt.Sprintf("imagetest harness %s passed in %s", result.Harness, result.Duration.Round(time.Second)),
+	}
+
+	if result.Failed() {
+		status.State = "failure"
+		status.Description = fmt.Sprintf("imagetest harness %s failed", result.Harness)
+		if failed := result.FailedSteps(); len(failed) > 0 {
+			status.Description += ": " + strings.Join(failed, ", ")
+		}
+	}
+
+	return g.update(ctx, status)
+}
+
+func (g *GitHubStatus) update(ctx context.Context, status gitHubStatus) error {
+	api := os.Getenv("GITHUB_API_URL")
+	if api == "" {
+		api = DefaultGitHubAPIURL
+	}
+
+	status.Context = g.Context
+	// descriptions longer than 140 characters are rejected by the API
+	if description := []rune(status.Description); len(description) > 140 {
+		status.Description = string(description[:137]) + "..."
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/statuses/%s", strings.TrimSuffix(api, "/"), g.Repo, g.Sha)
+	if err := postJSON(ctx, url, map[string]string{
+		"Accept":               "application/vnd.github+json",
+		"Authorization":        "Bearer " + g.Token,
+		"X-GitHub-Api-Version": "2022-11-28",
+	}, status); err != nil {
+		return fmt.Errorf("updating github commit status: %w", err)
+	}
+
+	return nil
+}
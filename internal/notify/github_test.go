@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestGitHubStatus(t *testing.T) {
+	testCases := map[string]struct {
+		result    Result
+		wantState string
+	}{
+		"success": {
+			result:    Result{Harness: "docker", Steps: []StepResult{{Feature: "f", Step: "s"}}},
+			wantState: "success",
+		},
+		"failure": {
+			result:    Result{Harness: "docker", Steps: []StepResult{{Feature: "f", Step: "s", Err: errors.New("boom")}}},
+			wantState: "failure",
+		},
+		"failure with a long description": {
+			result: Result{Harness: "docker", Steps: []StepResult{
+				{Feature: "fonctionnalité", Step: strings.Repeat("é", 100), Err: errors.New("boom")},
+				{Feature: "fonctionnalité", Step: strings.Repeat("ü", 100), Err: errors.New("boom")},
+			}},
+			wantState: "failure",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var got []gitHubStatus
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/repos/chainguard-dev/imagetest/statuses/0123456789abcdef" || r.Header.Get("Authorization") != "Bearer token" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				var status gitHubStatus
+				if err := json.NewDecoder(r.Body).Decode(&status); err != nil {
+					t.Errorf("decoding status: %v", err)
+				}
+				got = append(got, status)
+				w.WriteHeader(http.StatusCreated)
+			}))
+			defer srv.Close()
+			t.Setenv("GITHUB_API_URL", srv.URL)
+
+			g := &GitHubStatus{Token: "token", Repo: "chainguard-dev/imagetest", Sha: "0123456789abcdef", Context: "imagetest"}
+			if err := g.Started(context.Background(), "docker"); err != nil {
+				t.Fatal(err)
+			}
+			if err := g.Finished(context.Background(), tc.result); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(got) != 2 {
+				t.Fatalf("got %d statuses, want 2", len(got))
+			}
+			if got[0].State != "pending" || got[1].State != tc.wantState {
+				t.Errorf("states = %s -> %s, want pending -> %s", got[0].State, got[1].State, tc.wantState)
+			}
+			for _, status := range got {
+				if status.Context != "imagetest" {
+					t.Errorf("status context = %s, want imagetest", status.Context)
+				}
+				if !utf8.ValidString(status.Description) || utf8.RuneCountInString(status.Description) > 140 {
+					t.Errorf("invalid status description %q", status.Description)
+				}
+			}
+		})
+	}
+}
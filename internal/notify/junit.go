@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Name    string           `xml:"name,attr"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnit returns the harness run as a JUnit XML report, with one test suite per
+// feature and one test case per step.
+func JUnit(result Result) ([]byte, error) {
+	report := junitTestSuites{Name: result.Harness}
+
+	index := make(map[string]int)
+	var durations []time.Duration
+	for _, s := range result.Steps {
+		i, ok := index[s.Feature]
+		if !ok {
+			i = len(report.Suites)
+			index[s.Feature] = i
+			report.Suites = append(report.Suites, junitTestSuite{Name: s.Feature})
+			durations = append(durations, 0)
+		}
+		suite := &report.Suites[i]
+		durations[i] += s.Duration
+
+		tc := junitTestCase{
+			Name:      s.Step,
+			Classname: s.Feature,
+			Time:      fmt.Sprintf("%.3f", s.Duration.Seconds()),
+		}
+		if s.Err != nil {
+			tc.Failure = &junitFailure{Message: "step failed", Text: s.Err.Error()}
+			suite.Failures++
+		} else {
+			tc.SystemOut = s.Output
+		}
+
+		suite.Tests++
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	for i := range report.Suites {
+		report.Suites[i].Time = fmt.Sprintf("%.3f", durations[i].Seconds())
+	}
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding junit report: %w", err)
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
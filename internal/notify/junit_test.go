@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestJUnit(t *testing.T) {
+	testCases := map[string]struct {
+		steps        []StepResult
+		wantSuites   int
+		wantTests    []int
+		wantFailures []int
+	}{
+		"no steps": {},
+		"passing steps": {
+			steps: []StepResult{
+				{Feature: "a", Step: "one", Duration: time.Second, Output: "ok"},
+				{Feature: "a", Step: "two", Duration: time.Second},
+			},
+			wantSuites:   1,
+			wantTests:    []int{2},
+			wantFailures: []int{0},
+		},
+		"failures across features": {
+			steps: []StepResult{
+				{Feature: "a", Step: "one", Err: errors.New("boom")},
+				{Feature: "b", Step: "one"},
+				{Feature: "a", Step: "two"},
+			},
+			wantSuites:   2,
+			wantTests:    []int{2, 1},
+			wantFailures: []int{1, 0},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			data, err := JUnit(Result{Harness: "docker", Steps: tc.steps})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var report junitTestSuites
+			if err := xml.Unmarshal(data, &report); err != nil {
+				t.Fatalf("decoding report: %v", err)
+			}
+			if report.Name != "docker" {
+				t.Errorf("report name = %s, want docker", report.Name)
+			}
+			if len(report.Suites) != tc.wantSuites {
+				t.Fatalf("got %d suites, want %d", len(report.Suites), tc.wantSuites)
+			}
+			for i, suite := range report.Suites {
+				if suite.Tests != tc.wantTests[i] || len(suite.Cases) != tc.wantTests[i] {
+					t.Errorf("suite %s has %d tests, want %d", suite.Name, suite.Tests, tc.wantTests[i])
+				}
+				if suite.Failures != tc.wantFailures[i] {
+					t.Errorf("suite %s has %d failures, want %d", suite.Name, suite.Failures, tc.wantFailures[i])
+				}
+				for _, c := range suite.Cases {
+					if c.Classname != suite.Name {
+						t.Errorf("case %s classname = %s, want %s", c.Name, c.Classname, suite.Name)
+					}
+				}
+			}
+		})
+	}
+}
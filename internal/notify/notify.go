@@ -0,0 +1,183 @@
+// Package notify sends the outcome of harness runs to external services.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Notifier is notified of the lifecycle of a harness.
+type Notifier interface {
+	// Started is called once the harness is being created.
+	Started(ctx context.Context, harness string) error
+	// Finished is called once the harness failed to be created, or every
+	// feature using the harness is done with it.
+	Finished(ctx context.Context, result Result) error
+}
+
+// StepResult is the outcome of a single feature step run in the harness.
+type StepResult struct {
+	Feature  string
+	Step     string
+	Start    time.Time
+	Duration time.Duration
+	// Output is the combined stdout and stderr of the step, when the harness
+	// reports it.
+	Output string
+	Err    error
+}
+
+// Result is the outcome of a harness run.
+type Result struct {
+	Harness  string
+	Duration time.Duration
+	Steps    []StepResult
+	// Err is set when the harness itself failed.
+	Err error
+	// RunURL links to the CI run that ran the harness, empty when unknown.
+	RunURL string
+}
+
+// Failed returns true if the harness or any of its steps failed.
+func (r Result) Failed() bool {
+	return r.Err != nil || len(r.FailedSteps()) > 0
+}
+
+// FailedSteps returns the name of the failed steps, prefixed by the feature
+// they belong to.
+func (r Result) FailedSteps() []string {
+	var failed []string
+	for _, s := range r.Steps {
+		if s.Err != nil {
+			failed = append(failed, fmt.Sprintf("%s / %s", s.Feature, s.Step))
+		}
+	}
+	return failed
+}
+
+// Run tracks a harness run on behalf of its notifiers. It is safe for
+// concurrent use by the features sharing the harness.
+type Run struct {
+	harness   string
+	started   time.Time
+	notifiers []Notifier
+
+	mu    sync.Mutex
+	steps []StepResult
+}
+
+func NewRun(harness string, notifiers ...Notifier) *Run {
+	return &Run{
+		harness:   harness,
+		started:   time.Now(),
+		notifiers: notifiers,
+	}
+}
+
+// Start notifies that the harness is being created.
+func (r *Run) Start(ctx context.Context) error {
+	var errs []error
+	for _, n := range r.notifiers {
+		errs = append(errs, n.Started(ctx, r.harness))
+	}
+	return errors.Join(errs...)
+}
+
+// RecordStep records the outcome of a step.
+func (r *Run) RecordStep(result StepResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.steps = append(r.steps, result)
+}
+
+// Finish notifies the outcome of the harness. err is the error the harness
+// failed with, if any.
+func (r *Run) Finish(ctx context.Context, err error) error {
+	r.mu.Lock()
+	result := Result{
+		Harness:  r.harness,
+		Duration: time.Since(r.started),
+		Steps:    append([]StepResult(nil), r.steps...),
+		Err:      err,
+		RunURL:   RunURL(),
+	}
+	r.mu.Unlock()
+
+	var errs []error
+	for _, n := range r.notifiers {
+		errs = append(errs, n.Finished(ctx, result))
+	}
+	return errors.Join(errs...)
+}
+
+// RunURL returns the link to the current CI run, derived from the GitHub
+// Actions environment.
+func RunURL() string {
+	server, repo, id := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID")
+	if server == "" || repo == "" || id == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s/actions/runs/%s", server, repo, id)
+}
+
+// postJSON posts body encoded as JSON to url, failing on non 2xx responses.
+func postJSON(ctx context.Context, url string, headers map[string]string, body any) error {
+	return doJSON(ctx, http.MethodPost, url, headers, body, nil)
+}
+
+// doJSON sends body encoded as JSON to url, decoding the response into out
+// when it is not nil. Non 2xx responses are errors.
+func doJSON(ctx context.Context, method string, url string, headers map[string]string, body any, out any) error {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+		r = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, r)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	return send(req, out)
+}
+
+// send sends req, decoding the response into out when it is not nil. Non 2xx
+// responses are errors.
+func send(req *http.Request, out any) error {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, msg)
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("decoding response: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// PagerDutyEventsURL is the PagerDuty Events API v2 endpoint.
+const PagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutySeverities are the severities accepted by the PagerDuty Events API.
+var PagerDutySeverities = []string{"critical", "error", "warning", "info"}
+
+var _ Notifier = &PagerDuty{}
+
+// PagerDuty triggers a PagerDuty event when a harness fails. When DedupKey is
+// set, a passing harness resolves the event an earlier run triggered.
+type PagerDuty struct {
+	IntegrationKey string
+	Severity       string
+	// DedupKey deduplicates events, PagerDuty generates one when empty.
+	DedupKey string
+
+	// url overrides PagerDutyEventsURL.
+	url string
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key,omitempty"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+	Links       []pagerDutyLink   `json:"links,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"`
+	Component     string         `json:"component,omitempty"`
+	CustomDetails map[string]any `json:"custom_details,omitempty"`
+}
+
+type pagerDutyLink struct {
+	Href string `json:"href"`
+	Text string `json:"text"`
+}
+
+// Started implements Notifier.
+func (p *PagerDuty) Started(context.Context, string) error {
+	return nil
+}
+
+// Finished implements Notifier.
+func (p *PagerDuty) Finished(ctx context.Context, result Result) error {
+	if !result.Failed() {
+		return p.resolve(ctx)
+	}
+
+	details := map[string]any{
+		"failed_steps": result.FailedSteps(),
+		"duration":     result.Duration.String(),
+	}
+	if result.Err != nil {
+		details["error"] = result.Err.Error()
+	}
+
+	source, err := os.Hostname()
+	if err != nil {
+		source = "terraform-provider-imagetest"
+	}
+
+	event := pagerDutyEvent{
+		RoutingKey:  p.IntegrationKey,
+		EventAction: "trigger",
+		DedupKey:    p.DedupKey,
+		Payload: &pagerDutyPayload{
+			Summary:       p.summary(result),
+			Source:        source,
+			Severity:      p.Severity,
+			Component:     result.Harness,
+			CustomDetails: details,
+		},
+	}
+	if result.RunURL != "" {
+		event.Links = append(event.Links, pagerDutyLink{Href: result.RunURL, Text: "Terraform run"})
+	}
+
+	if err := postJSON(ctx, p.eventsURL(), nil, event); err != nil {
+		return fmt.Errorf("triggering pagerduty event: %w", err)
+	}
+
+	return nil
+}
+
+// resolve resolves the event triggered with the dedup key, which can't be
+// done without one.
+func (p *PagerDuty) resolve(ctx context.Context) error {
+	if p.DedupKey == "" {
+		return nil
+	}
+
+	if err := postJSON(ctx, p.eventsURL(), nil, pagerDutyEvent{
+		RoutingKey:  p.IntegrationKey,
+		EventAction: "resolve",
+		DedupKey:    p.DedupKey,
+	}); err != nil {
+		return fmt.Errorf("resolving pagerduty event: %w", err)
+	}
+
+	return nil
+}
+
+func (p *PagerDuty) eventsURL() string {
+	if p.url != "" {
+		return p.url
+	}
+	return PagerDutyEventsURL
+}
+
+func (p *PagerDuty) summary(result Result) string {
+	if failed := result.FailedSteps(); len(failed) > 0 {
+		return fmt.Sprintf("imagetest harness %s failed: %s", result.Harness, strings.Join(failed, ", "))
+	}
+	return fmt.Sprintf("imagetest harness %s failed: %s", result.Harness, result.Err)
+}
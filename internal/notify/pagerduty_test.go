@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPagerDuty(t *testing.T) {
+	failed := Result{Harness: "docker", Steps: []StepResult{{Feature: "f", Step: "s", Err: errors.New("boom")}}}
+	passed := Result{Harness: "docker", Steps: []StepResult{{Feature: "f", Step: "s"}}}
+
+	testCases := map[string]struct {
+		dedupKey string
+		results  []Result
+		want     []pagerDutyEvent
+	}{
+		"trigger": {
+			results: []Result{failed},
+			want:    []pagerDutyEvent{{RoutingKey: "key", EventAction: "trigger"}},
+		},
+		"passed without dedup key": {
+			results: []Result{passed},
+		},
+		"trigger then resolve with the same dedup key": {
+			dedupKey: "image-scan",
+			results:  []Result{failed, failed, passed},
+			want: []pagerDutyEvent{
+				{RoutingKey: "key", EventAction: "trigger", DedupKey: "image-scan"},
+				{RoutingKey: "key", EventAction: "trigger", DedupKey: "image-scan"},
+				{RoutingKey: "key", EventAction: "resolve", DedupKey: "image-scan"},
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var got []pagerDutyEvent
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var event pagerDutyEvent
+				if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+					t.Errorf("decoding event: %v", err)
+				}
+				got = append(got, event)
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer srv.Close()
+
+			p := &PagerDuty{IntegrationKey: "key", Severity: "critical", DedupKey: tc.dedupKey, url: srv.URL}
+			for _, result := range tc.results {
+				if err := p.Finished(context.Background(), result); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %d events, want %d", len(got), len(tc.want))
+			}
+			for i, event := range got {
+				want := tc.want[i]
+				if event.RoutingKey != want.RoutingKey || event.EventAction != want.EventAction || event.DedupKey != want.DedupKey {
+					t.Errorf("event %d = %+v, want %+v", i, event, want)
+				}
+				switch event.EventAction {
+				case "trigger":
+					if event.Payload == nil || event.Payload.Severity != "critical" || event.Payload.Component != "docker" {
+						t.Errorf("trigger event %d has payload %+v", i, event.Payload)
+					}
+				case "resolve":
+					if event.Payload != nil {
+						t.Errorf("resolve event %d has payload %+v", i, event.Payload)
+					}
+				}
+			}
+		})
+	}
+}
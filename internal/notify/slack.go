@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+var _ Notifier = &Slack{}
+
+// Slack posts a message to a Slack incoming webhook when a harness fails.
+type Slack struct {
+	WebhookURL string
+	// Channel overrides the default channel of the webhook, when set.
+	Channel string
+	// Mentions are the IDs of the users to mention in the message.
+	Mentions []string
+}
+
+type slackMessage struct {
+	Channel string `json:"channel,omitempty"`
+	Text    string `json:"text"`
+}
+
+// Started implements Notifier.
+func (s *Slack) Started(context.Context, string) error {
+	return nil
+}
+
+// Finished implements Notifier.
+func (s *Slack) Finished(ctx context.Context, result Result) error {
+	if !result.Failed() {
+		return nil
+	}
+
+	if err := postJSON(ctx, s.WebhookURL, nil, slackMessage{
+		Channel: s.Channel,
+		Text:    s.text(result),
+	}); err != nil {
+		return fmt.Errorf("sending slack notification: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Slack) text(result Result) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, ":x: imagetest harness `%s` failed", result.Harness)
+
+	if result.Err != nil {
+		fmt.Fprintf(&b, "\n*Error:* %s", result.Err)
+	}
+
+	if failed := result.FailedSteps(); len(failed) > 0 {
+		b.WriteString("\n*Failed steps:*")
+		for _, step := range failed {
+			fmt.Fprintf(&b, "\n• %s", step)
+		}
+	}
+
+	if result.RunURL != "" {
+		fmt.Fprintf(&b, "\n<%s|Terraform run>", result.RunURL)
+	}
+
+	if len(s.Mentions) > 0 {
+		mentions := make([]string, 0, len(s.Mentions))
+		for _, id := range s.Mentions {
+			mentions = append(mentions, fmt.Sprintf("<@%s>", id))
+		}
+		fmt.Fprintf(&b, "\ncc %s", strings.Join(mentions, " "))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,39 @@
+package notify
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSlackText(t *testing.T) {
+	testCases := map[string]struct {
+		slack  Slack
+		result Result
+		want   string
+	}{
+		"harness error": {
+			result: Result{Harness: "docker", Err: errors.New("boom")},
+			want:   ":x: imagetest harness `docker` failed\n*Error:* boom",
+		},
+		"failed steps": {
+			result: Result{Harness: "docker", Steps: []StepResult{
+				{Feature: "f", Step: "ok"},
+				{Feature: "f", Step: "ko", Err: errors.New("boom")},
+			}},
+			want: ":x: imagetest harness `docker` failed\n*Failed steps:*\n• f / ko",
+		},
+		"run url and mentions": {
+			slack:  Slack{Mentions: []string{"U1", "U2"}},
+			result: Result{Harness: "docker", Err: errors.New("boom"), RunURL: "https://example.com/run"},
+			want:   ":x: imagetest harness `docker` failed\n*Error:* boom\n<https://example.com/run|Terraform run>\ncc <@U1> <@U2>",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if got := tc.slack.text(tc.result); got != tc.want {
+				t.Errorf("text() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var _ Notifier = &Testmo{}
+
+// Testmo submits the harness steps as a Testmo automation run. The run is
+// created when the harness starts so its URL is known upfront, and the step
+// results are submitted once the harness completes.
+type Testmo struct {
+	URL       string
+	Token     string
+	ProjectID int64
+	// Milestone is the name of the milestone to link the run to, if any.
+	Milestone string
+
+	runID int64
+}
+
+type testmoID struct {
+	ID int64 `json:"id"`
+}
+
+type testmoRun struct {
+	Name        string `json:"name"`
+	Source      string `json:"source"`
+	MilestoneID int64  `json:"milestone_id,omitempty"`
+}
+
+type testmoMilestones struct {
+	Result []struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	} `json:"result"`
+	NextPage *int `json:"next_page"`
+}
+
+type testmoTests struct {
+	Tests []testmoTest `json:"tests"`
+}
+
+type testmoTest struct {
+	Name   string `json:"name"`
+	Folder string `json:"folder"`
+	Status string `json:"status"`
+	// Elapsed is the test duration in microseconds.
+	Elapsed int64 `json:"elapsed"`
+}
+
+// RunURL returns the URL of the Testmo run, empty until the harness started.
+func (t *Testmo) RunURL() string {
+	if t.runID == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s/automation/runs/view/%d", t.baseURL(), t.runID)
+}
+
+// Started implements Notifier.
+func (t *Testmo) Started(ctx context.Context, harness string) error {
+	run := testmoRun{
+		Name:   fmt.Sprintf("imagetest harness %s", harness),
+		Source: "imagetest",
+	}
+
+	if t.Milestone != "" {
+		id, err := t.milestoneID(ctx)
+		if err != nil {
+			return err
+		}
+		run.MilestoneID = id
+	}
+
+	var created testmoID
+	if err := t.do(ctx, http.MethodPost, fmt.Sprintf("/projects/%d/automation/runs", t.ProjectID), run, &created); err != nil {
+		return fmt.Errorf("creating testmo run: %w", err)
+	}
+	t.runID = created.ID
+
+	return nil
+}
+
+// Finished implements Notifier.
+func (t *Testmo) Finished(ctx context.Context, result Result) error {
+	if t.runID == 0 {
+		return fmt.Errorf("submitting testmo results: the testmo run was not created")
+	}
+
+	tests := testmoTests{Tests: make([]testmoTest, 0, len(result.Steps))}
+	for _, s := range result.Steps {
+		status := "passed"
+		if s.Err != nil {
+			status = "failed"
+		}
+		tests.Tests = append(tests.Tests, testmoTest{
+			Name:    s.Step,
+			Folder:  s.Feature,
+			Status:  status,
+			Elapsed: s.Duration.Microseconds(),
+		})
+	}
+
+	var thread testmoID
+	if err := t.do(ctx, http.MethodPost, fmt.Sprintf("/automation/runs/%d/threads", t.runID), struct{}{}, &thread); err != nil {
+		return fmt.Errorf("creating testmo thread: %w", err)
+	}
+
+	if err := t.do(ctx, http.MethodPost, fmt.Sprintf("/automation/runs/threads/%d/append", thread.ID), tests, nil); err != nil {
+		return fmt.Errorf("submitting testmo results: %w", err)
+	}
+
+	if err := t.do(ctx, http.MethodPost, fmt.Sprintf("/automation/runs/threads/%d/complete", thread.ID), struct{}{}, nil); err != nil {
+		return fmt.Errorf("completing testmo thread: %w", err)
+	}
+
+	if err := t.do(ctx, http.MethodPost, fmt.Sprintf("/automation/runs/%d/complete", t.runID), struct{}{}, nil); err != nil {
+		return fmt.Errorf("completing testmo run: %w", err)
+	}
+
+	return nil
+}
+
+// milestoneID looks up the ID of the milestone by name.
+func (t *Testmo) milestoneID(ctx context.Context) (int64, error) {
+	page := 1
+	for {
+		var milestones testmoMilestones
+		if err := t.do(ctx, http.MethodGet, fmt.Sprintf("/projects/%d/milestones?page=%d", t.ProjectID, page), nil, &milestones); err != nil {
+			return 0, fmt.Errorf("listing testmo milestones: %w", err)
+		}
+
+		for _, m := range milestones.Result {
+			if m.Name == t.Milestone {
+				return m.ID, nil
+			}
+		}
+
+		if milestones.NextPage == nil {
+			return 0, fmt.Errorf("testmo milestone %q not found in project %d", t.Milestone, t.ProjectID)
+		}
+		page = *milestones.NextPage
+	}
+}
+
+func (t *Testmo) do(ctx context.Context, method string, path string, body any, out any) error {
+	return doJSON(ctx, method, t.baseURL()+"/api/v1"+path, map[string]string{
+		"Authorization": "Bearer " + t.Token,
+	}, body, out)
+}
+
+func (t *Testmo) baseURL() string {
+	return strings.TrimSuffix(t.URL, "/")
+}
@@ -0,0 +1,121 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTestmo(t *testing.T) {
+	var calls []string
+	var run testmoRun
+	var tests testmoTests
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		calls = append(calls, r.Method+" "+r.URL.Path)
+
+		switch r.URL.Path {
+		case "/api/v1/projects/7/milestones":
+			if r.URL.Query().Get("page") == "1" {
+				_, _ = w.Write([]byte(`{"result":[{"id":1,"name":"other"}],"next_page":2}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"result":[{"id":2,"name":"v1.0"}],"next_page":null}`))
+		case "/api/v1/projects/7/automation/runs":
+			if err := json.NewDecoder(r.Body).Decode(&run); err != nil {
+				t.Errorf("decoding run: %v", err)
+			}
+			_ = json.NewEncoder(w).Encode(testmoID{ID: 42})
+		case "/api/v1/automation/runs/42/threads":
+			_ = json.NewEncoder(w).Encode(testmoID{ID: 5})
+		case "/api/v1/automation/runs/threads/5/append":
+			if err := json.NewDecoder(r.Body).Decode(&tests); err != nil {
+				t.Errorf("decoding tests: %v", err)
+			}
+		case "/api/v1/automation/runs/threads/5/complete", "/api/v1/automation/runs/42/complete":
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	tm := &Testmo{URL: srv.URL + "/", Token: "token", ProjectID: 7, Milestone: "v1.0"}
+
+	if err := tm.Finished(context.Background(), Result{}); err == nil {
+		t.Error("Finished() before Started() succeeded")
+	}
+	if tm.RunURL() != "" {
+		t.Errorf("RunURL() = %s before the run was created", tm.RunURL())
+	}
+
+	if err := tm.Started(context.Background(), "docker"); err != nil {
+		t.Fatal(err)
+	}
+	if run.MilestoneID != 2 || run.Source != "imagetest" {
+		t.Errorf("unexpected run %+v", run)
+	}
+	if want := srv.URL + "/automation/runs/view/42"; tm.RunURL() != want {
+		t.Errorf("RunURL() = %s, want %s", tm.RunURL(), want)
+	}
+
+	if err := tm.Finished(context.Background(), Result{
+		Harness: "docker",
+		Steps: []StepResult{
+			{Feature: "f", Step: "ok", Duration: time.Millisecond},
+			{Feature: "f", Step: "ko", Err: errors.New("boom")},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []testmoTest{
+		{Name: "ok", Folder: "f", Status: "passed", Elapsed: 1000},
+		{Name: "ko", Folder: "f", Status: "failed"},
+	}
+	if len(tests.Tests) != len(want) {
+		t.Fatalf("submitted %d tests, want %d", len(tests.Tests), len(want))
+	}
+	for i := range want {
+		if tests.Tests[i] != want[i] {
+			t.Errorf("test %d = %+v, want %+v", i, tests.Tests[i], want[i])
+		}
+	}
+
+	// the results are submitted before the thread and the run are completed
+	wantCalls := []string{
+		"GET /api/v1/projects/7/milestones",
+		"GET /api/v1/projects/7/milestones",
+		"POST /api/v1/projects/7/automation/runs",
+		"POST /api/v1/automation/runs/42/threads",
+		"POST /api/v1/automation/runs/threads/5/append",
+		"POST /api/v1/automation/runs/threads/5/complete",
+		"POST /api/v1/automation/runs/42/complete",
+	}
+	if len(calls) != len(wantCalls) {
+		t.Fatalf("calls = %v, want %v", calls, wantCalls)
+	}
+	for i := range wantCalls {
+		if calls[i] != wantCalls[i] {
+			t.Errorf("call %d = %s, want %s", i, calls[i], wantCalls[i])
+		}
+	}
+}
+
+func TestTestmoMilestoneNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result":[],"next_page":null}`))
+	}))
+	defer srv.Close()
+
+	tm := &Testmo{URL: srv.URL, Token: "token", ProjectID: 7, Milestone: "missing"}
+	if err := tm.Started(context.Background(), "docker"); err == nil {
+		t.Error("Started() with a missing milestone succeeded")
+	}
+}
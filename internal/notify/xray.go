@@ -0,0 +1,134 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// XrayCloudURL is the Xray Cloud API. Xray Server and Data Center expose a
+// different API and are not supported.
+const XrayCloudURL = "https://xray.cloud.getxray.app"
+
+var _ Notifier = &Xray{}
+
+// Xray imports the harness run to a Jira Xray test execution. The execution is
+// created when the harness starts so its key is known upfront, and the JUnit
+// report of the run is imported to it once the harness completes.
+type Xray struct {
+	ClientID     string
+	ClientSecret string
+	ProjectKey   string
+	// TestPlanKey is the test plan to link the execution to, if any.
+	TestPlanKey string
+
+	// url overrides XrayCloudURL.
+	url          string
+	executionKey string
+}
+
+type xrayExecution struct {
+	Info  xrayExecutionInfo `json:"info"`
+	Tests []struct{}        `json:"tests"`
+}
+
+type xrayExecutionInfo struct {
+	Project     string `json:"project"`
+	Summary     string `json:"summary"`
+	TestPlanKey string `json:"testPlanKey,omitempty"`
+}
+
+type xrayImported struct {
+	Key string `json:"key"`
+}
+
+// ExecutionKey returns the key of the test execution, empty until the harness
+// started.
+func (x *Xray) ExecutionKey() string {
+	return x.executionKey
+}
+
+// Started implements Notifier.
+func (x *Xray) Started(ctx context.Context, harness string) error {
+	token, err := x.authenticate(ctx)
+	if err != nil {
+		return err
+	}
+
+	var imported xrayImported
+	if err := doJSON(ctx, http.MethodPost, x.baseURL()+"/api/v2/import/execution", map[string]string{
+		"Authorization": "Bearer " + token,
+	}, xrayExecution{
+		Info: xrayExecutionInfo{
+			Project:     x.ProjectKey,
+			Summary:     fmt.Sprintf("imagetest harness %s", harness),
+			TestPlanKey: x.TestPlanKey,
+		},
+		Tests: []struct{}{},
+	}, &imported); err != nil {
+		return fmt.Errorf("creating xray test execution: %w", err)
+	}
+	x.executionKey = imported.Key
+
+	return nil
+}
+
+// Finished implements Notifier.
+func (x *Xray) Finished(ctx context.Context, result Result) error {
+	if x.executionKey == "" {
+		return fmt.Errorf("importing xray results: the xray test execution was not created")
+	}
+
+	report, err := JUnit(result)
+	if err != nil {
+		return err
+	}
+
+	token, err := x.authenticate(ctx)
+	if err != nil {
+		return err
+	}
+
+	q := url.Values{
+		"projectKey":  {x.ProjectKey},
+		"testExecKey": {x.executionKey},
+	}
+	if x.TestPlanKey != "" {
+		q.Set("testPlanKey", x.TestPlanKey)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, x.baseURL()+"/api/v2/import/execution/junit?"+q.Encode(), bytes.NewReader(report))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/xml")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	if err := send(req, nil); err != nil {
+		return fmt.Errorf("importing xray results: %w", err)
+	}
+
+	return nil
+}
+
+// authenticate returns a token for the Xray API. Tokens are short lived, so a
+// new one is requested for every call.
+func (x *Xray) authenticate(ctx context.Context) (string, error) {
+	var token string
+	if err := doJSON(ctx, http.MethodPost, x.baseURL()+"/api/v2/authenticate", nil, map[string]string{
+		"client_id":     x.ClientID,
+		"client_secret": x.ClientSecret,
+	}, &token); err != nil {
+		return "", fmt.Errorf("authenticating to xray: %w", err)
+	}
+	return token, nil
+}
+
+func (x *Xray) baseURL() string {
+	if x.url != "" {
+		return x.url
+	}
+	return XrayCloudURL
+}
@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestXray(t *testing.T) {
+	var execution xrayExecution
+	var query map[string]string
+	var report junitTestSuites
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/authenticate" {
+			_ = json.NewEncoder(w).Encode("token")
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/api/v2/import/execution":
+			if err := json.NewDecoder(r.Body).Decode(&execution); err != nil {
+				t.Errorf("decoding execution: %v", err)
+			}
+			_ = json.NewEncoder(w).Encode(xrayImported{Key: "PRJ-1"})
+		case "/api/v2/import/execution/junit":
+			query = map[string]string{}
+			for k := range r.URL.Query() {
+				query[k] = r.URL.Query().Get(k)
+			}
+			body, _ := io.ReadAll(r.Body)
+			if err := xml.Unmarshal(body, &report); err != nil {
+				t.Errorf("decoding junit report: %v", err)
+			}
+			_ = json.NewEncoder(w).Encode(xrayImported{Key: "PRJ-1"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	x := &Xray{ClientID: "id", ClientSecret: "secret", ProjectKey: "PRJ", TestPlanKey: "PRJ-0", url: srv.URL}
+
+	if err := x.Finished(context.Background(), Result{}); err == nil {
+		t.Error("Finished() before Started() succeeded")
+	}
+
+	if err := x.Started(context.Background(), "docker"); err != nil {
+		t.Fatal(err)
+	}
+	if x.ExecutionKey() != "PRJ-1" {
+		t.Errorf("execution key = %s, want PRJ-1", x.ExecutionKey())
+	}
+	if execution.Info.Project != "PRJ" || execution.Info.TestPlanKey != "PRJ-0" {
+		t.Errorf("unexpected execution info %+v", execution.Info)
+	}
+
+	if err := x.Finished(context.Background(), Result{
+		Harness: "docker",
+		Steps:   []StepResult{{Feature: "f", Step: "s", Err: errors.New("boom")}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"projectKey": "PRJ", "testExecKey": "PRJ-1", "testPlanKey": "PRJ-0"}
+	for k, v := range want {
+		if query[k] != v {
+			t.Errorf("query %s = %q, want %q", k, query[k], v)
+		}
+	}
+	if len(report.Suites) != 1 || report.Suites[0].Failures != 1 {
+		t.Errorf("unexpected junit report %+v", report)
+	}
+}
@@ -3,12 +3,23 @@ package provider
 import (
 	"context"
 	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
 
+	"github.com/chainguard-dev/terraform-provider-imagetest/internal/containers/provider"
+	"github.com/chainguard-dev/terraform-provider-imagetest/internal/harnesses/base"
 	"github.com/chainguard-dev/terraform-provider-imagetest/internal/log"
+	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/volume"
+	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/objectplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 )
@@ -17,10 +28,45 @@ var (
 	_ resource.Resource                = &ContainerVolumeResource{}
 	_ resource.ResourceWithConfigure   = &ContainerVolumeResource{}
 	_ resource.ResourceWithImportState = &ContainerVolumeResource{}
+	_ resource.ResourceWithModifyPlan  = &ContainerVolumeResource{}
 )
 
 const metadataSuffix = "_container_volume"
 
+// VolumeInitMountPath is where the volume is mounted in its init containers.
+const VolumeInitMountPath = "/mnt/volume"
+
+// VolumeHelperImage is the image of the short lived containers inspecting the
+// files in a volume.
+const VolumeHelperImage = "cgr.dev/chainguard/wolfi-base:latest"
+
+// Volume cleanup strategies, see the cleanup_strategy attribute.
+const (
+	VolumeCleanupStrategyDestroy       = "destroy"
+	VolumeCleanupStrategyKeep          = "keep"
+	VolumeCleanupStrategyKeepOnFailure = "keep_on_failure"
+)
+
+// Volume access modes, see the access_mode attribute.
+const (
+	VolumeAccessModeReadWriteOnce = "ReadWriteOnce"
+	VolumeAccessModeReadWriteMany = "ReadWriteMany"
+	VolumeAccessModeReadOnlyMany  = "ReadOnlyMany"
+)
+
+// Volume reclaim policies, see the reclaim_policy attribute.
+const (
+	VolumeReclaimPolicyRetain = "Retain"
+	VolumeReclaimPolicyDelete = "Delete"
+)
+
+// Volume retention policies, see the retention_policy attribute.
+const (
+	VolumeRetentionPolicyDestroy          = "destroy_on_terraform_destroy"
+	VolumeRetentionPolicyRetain           = "retain"
+	VolumeRetentionPolicyRetainIfNonEmpty = "retain_if_non_empty"
+)
+
 type ContainerVolumeResource struct {
 	store *ProviderStore
 }
@@ -29,6 +75,34 @@ type ContainerVolumeResourceModel struct {
 	Id        types.String             `tfsdk:"id"`
 	Name      types.String             `tfsdk:"name"`
 	Inventory InventoryDataSourceModel `tfsdk:"inventory"`
+
+	CleanupStrategy types.String                        `tfsdk:"cleanup_strategy"`
+	InitContainers  []ContainerVolumeInitContainerModel `tfsdk:"init_containers"`
+	VerifyChecksum  *ContainerVolumeVerifyChecksumModel `tfsdk:"verify_checksum"`
+	RetentionPolicy types.String                        `tfsdk:"retention_policy"`
+	PreDeleteHook   *ContainerVolumePreDeleteHookModel  `tfsdk:"pre_delete_hook"`
+
+	EncryptionAtRest        types.Bool `tfsdk:"encryption_at_rest"`
+	RequireEncryptionAtRest types.Bool `tfsdk:"require_encryption_at_rest"`
+
+	AccessMode    types.String `tfsdk:"access_mode"`
+	ReclaimPolicy types.String `tfsdk:"reclaim_policy"`
+}
+
+type ContainerVolumePreDeleteHookModel struct {
+	Image   types.String `tfsdk:"image"`
+	Command types.String `tfsdk:"command"`
+}
+
+type ContainerVolumeVerifyChecksumModel struct {
+	Algorithm types.String `tfsdk:"algorithm"`
+	Expected  types.Map    `tfsdk:"expected"`
+}
+
+type ContainerVolumeInitContainerModel struct {
+	Image       types.String `tfsdk:"image"`
+	Command     types.String `tfsdk:"command"`
+	Environment types.Map    `tfsdk:"environment"`
 }
 
 func NewContainerVolumeResource() resource.Resource {
@@ -79,10 +153,115 @@ func ContainerVolumeResourceAttributes() map[string]schema.Attribute {
 			Description: "The unique identifier for this volume. This is generated from the volume name and inventory seed.",
 			Computed:    true,
 		},
+		"init_containers": schema.ListNestedAttribute{
+			Description: "Containers run in order once the volume is created to populate it, with the volume mounted at /mnt/volume. Each container must exit successfully before the next one starts. Changing the init containers recreates the volume.",
+			Optional:    true,
+			PlanModifiers: []planmodifier.List{
+				listplanmodifier.RequiresReplace(),
+			},
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: map[string]schema.Attribute{
+					"image": schema.StringAttribute{
+						Description: "The image reference of the init container.",
+						Required:    true,
+					},
+					"command": schema.StringAttribute{
+						Description: "The command run in the init container with /bin/sh -c.",
+						Required:    true,
+					},
+					"environment": schema.MapAttribute{
+						Description: "Environment variables to set in the init container.",
+						Optional:    true,
+						ElementType: types.StringType,
+					},
+				},
+			},
+		},
+		"verify_checksum": schema.SingleNestedAttribute{
+			Description: "Verifies the checksums of files in the volume once it is populated by the init containers. Changing it recreates the volume.",
+			Optional:    true,
+			PlanModifiers: []planmodifier.Object{
+				objectplanmodifier.RequiresReplace(),
+			},
+			Attributes: map[string]schema.Attribute{
+				"algorithm": schema.StringAttribute{
+					Description: "The checksum algorithm, one of: sha256, md5.",
+					Required:    true,
+				},
+				"expected": schema.MapAttribute{
+					Description: "The expected checksums, keyed by the path of the file relative to the root of the volume.",
+					Required:    true,
+					ElementType: types.StringType,
+				},
+			},
+		},
+		"retention_policy": schema.StringAttribute{
+			Description: "Whether the volume outlives the Terraform lifecycle, one of: destroy_on_terraform_destroy (the volume is removed as set by cleanup_strategy), retain (the volume is never removed), retain_if_non_empty (the volume is only removed when it holds no files). Defaults to destroy_on_terraform_destroy.",
+			Optional:    true,
+		},
+		"pre_delete_hook": schema.SingleNestedAttribute{
+			Description: "A container run with the volume mounted at /mnt/volume right before the volume is removed, e.g. to flush data gracefully. The volume is not removed when the hook fails.",
+			Optional:    true,
+			Attributes: map[string]schema.Attribute{
+				"image": schema.StringAttribute{
+					Description: "The image reference of the hook container.",
+					Required:    true,
+				},
+				"command": schema.StringAttribute{
+					Description: "The command run in the hook container with /bin/sh -c.",
+					Required:    true,
+				},
+			},
+		},
+		"encryption_at_rest": schema.BoolAttribute{
+			Description: "Whether the Docker daemon reports the volume as encrypted at rest, e.g. with dm-crypt, in the volume status. Most local drivers don't report it.",
+			Computed:    true,
+		},
+		"require_encryption_at_rest": schema.BoolAttribute{
+			Description: "Whether to fail the creation of the volume when it isn't reported as encrypted at rest.",
+			Optional:    true,
+		},
+		"access_mode": schema.StringAttribute{
+			Description: "How the volume may be mounted, one of: ReadWriteOnce, ReadWriteMany, ReadOnlyMany. The mode is validated against the access modes the volume driver reports in the volume status. Most local drivers only support ReadWriteOnce.",
+			Optional:    true,
+		},
+		"reclaim_policy": schema.StringAttribute{
+			Description: "What happens to the volume data once the containers mounting it are stopped, one of: Retain (the volume is never removed, even by terraform destroy), Delete (the volume is force removed once the cleanup_strategy and retention_policy allow it; forcing ignores volume driver errors, but a volume still in use by a container is not removed). Retain takes precedence over the cleanup_strategy and retention_policy.",
+			Optional:    true,
+		},
+		"cleanup_strategy": schema.StringAttribute{
+			Description: "When the volume is removed, one of: destroy (when the harnesses mounting it are torn down or the volume is destroyed), keep (never), keep_on_failure (as destroy, unless a feature of a harness mounting it failed). Defaults to the provider volume_cleanup_strategy.",
+			Optional:    true,
+		},
+	}
+}
+
+func (r *ContainerVolumeResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// the reclaim policy being planned, or the one in the state on destroy
+	policy := path.Root("reclaim_policy")
+	var reclaim types.String
+	if req.Plan.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, policy, &reclaim)...)
+	} else {
+		resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, policy, &reclaim)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if reclaim.ValueString() == VolumeReclaimPolicyRetain {
+		resp.Diagnostics.AddWarning("volume will be retained", "reclaim_policy is Retain, so the volume and its data are never removed by the provider, even by terraform destroy")
 	}
 }
 
 func (r *ContainerVolumeResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	release, diags := r.store.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
+	defer release()
+
 	ctx = log.WithCtx(ctx, r.store.Logger())
 
 	var data ContainerVolumeResourceModel
@@ -91,6 +270,11 @@ func (r *ContainerVolumeResource) Create(ctx context.Context, req resource.Creat
 		return
 	}
 
+	if err := data.validate(); err != nil {
+		resp.Diagnostics.AddError("invalid resource input", err.Error())
+		return
+	}
+
 	inv := InventoryDataSourceModel{}
 	if diags := req.Config.GetAttribute(ctx, path.Root("inventory"), &inv); diags.HasError() {
 		return
@@ -103,15 +287,63 @@ func (r *ContainerVolumeResource) Create(ctx context.Context, req resource.Creat
 	}
 
 	id := fmt.Sprintf("%s-%s", data.Name.ValueString(), invEnc)
-	_, err = r.store.cli.VolumeCreate(ctx, volume.CreateOptions{
-		Name: id,
-	})
-	if err != nil {
-		resp.Diagnostics.AddError("failed to create volume", err.Error())
-		return
+	data.EncryptionAtRest = basetypes.NewBoolNull()
+	if r.store.dryRun {
+		resp.Diagnostics.AddWarning(fmt.Sprintf("skipping volume [%s] creation", id), "dry_run_all is set on the provider")
+	} else {
+		_, err = r.store.cli.VolumeCreate(ctx, volume.CreateOptions{
+			Name:   id,
+			Labels: r.store.cli.Labels(),
+		})
+		if err != nil {
+			resp.Diagnostics.AddError("failed to create volume", err.Error())
+			return
+		}
+
+		vol, err := r.store.cli.VolumeInspect(ctx, id)
+		if err != nil {
+			resp.Diagnostics.AddError("failed to inspect volume", err.Error())
+			return
+		}
+
+		if mode := data.AccessMode.ValueString(); mode != "" {
+			modes, reported := volumeAccessModes(vol.Status)
+			if !reported {
+				if mode != VolumeAccessModeReadWriteOnce {
+					resp.Diagnostics.AddWarning(fmt.Sprintf("volume driver %q doesn't report its access modes", vol.Driver), fmt.Sprintf("access_mode %s can't be validated for volume [%s], most local drivers only support ReadWriteOnce", mode, id))
+				}
+			} else if !slices.Contains(modes, mode) {
+				resp.Diagnostics.AddError("unsupported volume access mode", fmt.Sprintf("the volume driver %q only supports the access modes %v, not %s", vol.Driver, modes, mode))
+				if err := r.store.cli.RemoveVolume(ctx, id, true); err != nil {
+					resp.Diagnostics.AddWarning("failed to remove volume", err.Error())
+				}
+				return
+			}
+		}
+
+		encrypted := volumeEncrypted(vol.Status)
+		data.EncryptionAtRest = basetypes.NewBoolValue(encrypted)
+
+		if data.RequireEncryptionAtRest.ValueBool() && !encrypted {
+			resp.Diagnostics.AddError("volume is not encrypted at rest", fmt.Sprintf("the volume driver %q doesn't report volume [%s] as encrypted at rest, but require_encryption_at_rest is set", vol.Driver, id))
+			if err := r.store.cli.RemoveVolume(ctx, id, true); err != nil {
+				resp.Diagnostics.AddWarning("failed to remove volume", err.Error())
+			}
+			return
+		}
+
+		if err := r.initialize(ctx, id, data.InitContainers, data.VerifyChecksum); err != nil {
+			resp.Diagnostics.AddError("failed to initialize volume", err.Error())
+			// don't leak a partially populated volume that isn't in the state
+			if err := r.store.cli.RemoveVolume(ctx, id, true); err != nil {
+				resp.Diagnostics.AddWarning("failed to remove volume", err.Error())
+			}
+			return
+		}
 	}
 
 	data.Id = basetypes.NewStringValue(id)
+	r.store.volumes.Set(id, data)
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -126,11 +358,20 @@ func (r *ContainerVolumeResource) Read(ctx context.Context, req resource.ReadReq
 		return
 	}
 
+	r.store.volumes.Set(data.Id.ValueString(), data)
+
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *ContainerVolumeResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	release, diags := r.store.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
+	defer release()
+
 	var data ContainerVolumeResourceModel
 
 	// Read Terraform plan data into the model
@@ -140,11 +381,39 @@ func (r *ContainerVolumeResource) Update(ctx context.Context, req resource.Updat
 		return
 	}
 
+	// the volume isn't recreated, so the computed attributes are unchanged
+	var state ContainerVolumeResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Id = state.Id
+	data.EncryptionAtRest = state.EncryptionAtRest
+
+	if !r.store.dryRun && data.RequireEncryptionAtRest.ValueBool() && !data.EncryptionAtRest.ValueBool() {
+		resp.Diagnostics.AddError("volume is not encrypted at rest", fmt.Sprintf("volume [%s] isn't encrypted at rest, but require_encryption_at_rest is set", data.Id.ValueString()))
+		return
+	}
+
+	if err := data.validate(); err != nil {
+		resp.Diagnostics.AddError("invalid resource input", err.Error())
+		return
+	}
+
+	r.store.volumes.Set(data.Id.ValueString(), data)
+
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *ContainerVolumeResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	release, diags := r.store.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
+	defer release()
+
 	var data ContainerVolumeResourceModel
 
 	// Read Terraform prior state data into the model
@@ -153,6 +422,244 @@ func (r *ContainerVolumeResource) Delete(ctx context.Context, req resource.Delet
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	if r.store.dryRun {
+		return
+	}
+
+	// failures are only known to the run that tested the harnesses, so a
+	// destroy removes volumes kept on failure
+	r.store.volumes.Set(data.Id.ValueString(), data)
+	if err := r.store.RemoveVolume(ctx, data.Id.ValueString(), false); err != nil {
+		resp.Diagnostics.AddError("failed to remove volume", err.Error())
+		return
+	}
+	r.store.volumes.Delete(data.Id.ValueString())
+}
+
+// validate checks the attributes of the volume that aren't validated by the
+// schema.
+func (m ContainerVolumeResourceModel) validate() error {
+	if !m.CleanupStrategy.IsNull() && !validVolumeCleanupStrategy(m.CleanupStrategy.ValueString()) {
+		return fmt.Errorf("invalid cleanup_strategy %q, must be one of: destroy, keep, keep_on_failure", m.CleanupStrategy.ValueString())
+	}
+
+	switch m.RetentionPolicy.ValueString() {
+	case "", VolumeRetentionPolicyDestroy, VolumeRetentionPolicyRetain, VolumeRetentionPolicyRetainIfNonEmpty:
+	default:
+		return fmt.Errorf("invalid retention_policy %q, must be one of: destroy_on_terraform_destroy, retain, retain_if_non_empty", m.RetentionPolicy.ValueString())
+	}
+
+	switch m.ReclaimPolicy.ValueString() {
+	case "", VolumeReclaimPolicyRetain, VolumeReclaimPolicyDelete:
+	default:
+		return fmt.Errorf("invalid reclaim_policy %q, must be one of: Retain, Delete", m.ReclaimPolicy.ValueString())
+	}
+
+	if !validVolumeAccessMode(m.AccessMode.ValueString()) {
+		return fmt.Errorf("invalid access_mode %q, must be one of: ReadWriteOnce, ReadWriteMany, ReadOnlyMany", m.AccessMode.ValueString())
+	}
+
+	if m.VerifyChecksum != nil {
+		switch m.VerifyChecksum.Algorithm.ValueString() {
+		case "sha256", "md5":
+		default:
+			return fmt.Errorf("invalid verify_checksum algorithm %q, must be one of: sha256, md5", m.VerifyChecksum.Algorithm.ValueString())
+		}
+	}
+
+	return nil
+}
+
+// initialize runs the init containers with the volume mounted, one at a time,
+// then verifies the checksums of the files they populated. Each container is
+// removed once it exits.
+func (r *ContainerVolumeResource) initialize(ctx context.Context, id string, inits []ContainerVolumeInitContainerModel, verify *ContainerVolumeVerifyChecksumModel) error {
+	for i, init := range inits {
+		envs := make(provider.Env)
+		if diags := init.Environment.ElementsAs(ctx, &envs, false); diags.HasError() {
+			return fmt.Errorf("invalid init container %d environment", i)
+		}
+
+		out, err := runInVolume(ctx, r.store.cli, fmt.Sprintf("%s-init-%d", id, i), id, init.Image.ValueString(), init.Command.ValueString(), envs, false)
+		if err != nil {
+			return fmt.Errorf("running init container %d: %w", i, err)
+		}
+
+		log.Info(ctx, "ran volume init container", "volume", id, "image", init.Image.ValueString(), "out", out)
+	}
+
+	if verify == nil {
+		return nil
+	}
+
+	return r.verify(ctx, id, verify)
+}
+
+// verify checks the files of the volume have the expected checksums, using the
+// <algorithm>sum -c utilities in a short lived container.
+func (r *ContainerVolumeResource) verify(ctx context.Context, id string, verify *ContainerVolumeVerifyChecksumModel) error {
+	expected := make(map[string]string)
+	if diags := verify.Expected.ElementsAs(ctx, &expected, false); diags.HasError() {
+		return fmt.Errorf("invalid verify_checksum expected checksums")
+	}
+
+	paths := make([]string, 0, len(expected))
+	for p := range expected {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var checksums strings.Builder
+	for _, p := range paths {
+		fmt.Fprintf(&checksums, "%s  %s\n", expected[p], strings.TrimPrefix(p, "/"))
+	}
+
+	cmd := fmt.Sprintf(`cd %s && printf '%%s' "$CHECKSUMS" | %ssum -c -`, VolumeInitMountPath, verify.Algorithm.ValueString())
+	if _, err := runInVolume(ctx, r.store.cli, id+"-verify", id, VolumeHelperImage, cmd, provider.Env{"CHECKSUMS": checksums.String()}, true); err != nil {
+		return fmt.Errorf("verifying volume checksums: %w", err)
+	}
+
+	return nil
+}
+
+// volumeEmpty returns true when the volume with the given ID holds no files,
+// listing its contents in a short lived container.
+func volumeEmpty(ctx context.Context, cli *provider.DockerClient, id string) (bool, error) {
+	out, err := runInVolume(ctx, cli, id+"-ls", id, VolumeHelperImage, "ls -A "+VolumeInitMountPath, nil, true)
+	if err != nil {
+		return false, fmt.Errorf("listing volume contents: %w", err)
+	}
+
+	return strings.TrimSpace(out) == "", nil
+}
+
+// runPreDeleteHook runs the pre delete hook of the volume, if any.
+func runPreDeleteHook(ctx context.Context, cli *provider.DockerClient, vol ContainerVolumeResourceModel) error {
+	if vol.PreDeleteHook == nil {
+		return nil
+	}
+
+	id := vol.Id.ValueString()
+	out, err := runInVolume(ctx, cli, id+"-pre-delete", id, vol.PreDeleteHook.Image.ValueString(), vol.PreDeleteHook.Command.ValueString(), nil, false)
+	if err != nil {
+		return fmt.Errorf("running pre delete hook: %w", err)
+	}
+
+	log.Info(ctx, "ran volume pre delete hook", "volume", id, "image", vol.PreDeleteHook.Image.ValueString(), "out", out)
+
+	return nil
+}
+
+// runInVolume runs cmd with /bin/sh -c in a short lived container with the
+// volume mounted at VolumeInitMountPath, returning the combined stdout and
+// stderr of the container.
+func runInVolume(ctx context.Context, cli *provider.DockerClient, containerName string, volumeId string, image string, cmd string, envs provider.Env, readOnly bool) (string, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return "", fmt.Errorf("invalid image reference: %w", err)
+	}
+
+	c := provider.NewDocker(containerName, cli, provider.DockerRequest{
+		ContainerRequest: provider.ContainerRequest{
+			Ref:        ref,
+			Entrypoint: base.DefaultEntrypoint(),
+			Cmd:        []string{cmd},
+			Env:        envs,
+		},
+		Mounts: []mount.Mount{{
+			Type:     mount.TypeVolume,
+			Source:   volumeId,
+			Target:   VolumeInitMountPath,
+			ReadOnly: readOnly,
+		}},
+	})
+
+	r, err := c.Run(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+
+	return string(out), nil
+}
+
+// volumeEncrypted returns true when the status of a volume, as reported by its
+// driver, says it is encrypted at rest. There is no standard status key for
+// this, so the common spellings are checked.
+func volumeEncrypted(status map[string]interface{}) bool {
+	for k, v := range status {
+		switch strings.ToLower(k) {
+		case "encrypted", "encryption", "encryption_at_rest":
+		default:
+			continue
+		}
+
+		switch v := v.(type) {
+		case bool:
+			return v
+		case string:
+			switch strings.ToLower(v) {
+			case "", "false", "none", "off", "disabled":
+				return false
+			}
+			return true
+		}
+	}
+
+	return false
+}
+
+// volumeAccessModes returns the access modes the driver of a volume reports in
+// its status, and whether it reports any.
+func volumeAccessModes(status map[string]interface{}) ([]string, bool) {
+	for k, v := range status {
+		switch strings.ToLower(k) {
+		case "access_modes", "accessmodes", "access_mode", "accessmode":
+		default:
+			continue
+		}
+
+		switch v := v.(type) {
+		case string:
+			modes := strings.Split(v, ",")
+			for i := range modes {
+				modes[i] = strings.TrimSpace(modes[i])
+			}
+			return modes, true
+		case []interface{}:
+			modes := make([]string, 0, len(v))
+			for _, m := range v {
+				if s, ok := m.(string); ok {
+					modes = append(modes, s)
+				}
+			}
+			return modes, true
+		}
+	}
+
+	return nil, false
+}
+
+// validVolumeAccessMode returns true for the known access modes, and unset.
+func validVolumeAccessMode(mode string) bool {
+	switch mode {
+	case "", VolumeAccessModeReadWriteOnce, VolumeAccessModeReadWriteMany, VolumeAccessModeReadOnlyMany:
+		return true
+	}
+	return false
+}
+
+func validVolumeCleanupStrategy(strategy string) bool {
+	switch strategy {
+	case VolumeCleanupStrategyDestroy, VolumeCleanupStrategyKeep, VolumeCleanupStrategyKeepOnFailure:
+		return true
+	}
+	return false
 }
 
 func (r *ContainerVolumeResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
@@ -1,24 +1,617 @@
 package provider
 
 import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
 	"testing"
 
+	cprovider "github.com/chainguard-dev/terraform-provider-imagetest/internal/containers/provider"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
 
 func TestAccContainerVolumeResource(t *testing.T) {
+	testCases := map[string][]resource.TestStep{
+		"basic volume": {
+			{
+				ExpectNonEmptyPlan: true,
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_container_volume" "test" {
+  name      = "test"
+  inventory = data.imagetest_inventory.this
+}
+        `,
+			},
+		},
+		"with cleanup strategy": {
+			{
+				ExpectNonEmptyPlan: true,
+				Config: `
+provider "imagetest" {
+  volume_cleanup_strategy = "keep"
+}
+
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_container_volume" "test" {
+  name             = "test"
+  inventory        = data.imagetest_inventory.this
+  cleanup_strategy = "keep_on_failure"
+}
+
+resource "imagetest_harness_docker" "test" {
+  name      = "test"
+  inventory = data.imagetest_inventory.this
+  volumes = [
+    {
+      source      = imagetest_container_volume.test
+      destination = "/volume"
+    },
+  ]
+}
+
+resource "imagetest_feature" "test" {
+  name    = "Volume cleanup"
+  harness = imagetest_harness_docker.test
+  steps = [
+    {
+      name = "Write to the volume"
+      cmd  = "touch /volume/hello"
+    },
+  ]
+}
+        `,
+			},
+		},
+		"with init containers": {
+			{
+				ExpectNonEmptyPlan: true,
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_container_volume" "test" {
+  name      = "test"
+  inventory = data.imagetest_inventory.this
+  init_containers = [
+    {
+      image   = "cgr.dev/chainguard/wolfi-base:latest"
+      command = "echo $GREETING > /mnt/volume/hello"
+      environment = {
+        GREETING = "hello"
+      }
+    },
+    {
+      image   = "cgr.dev/chainguard/wolfi-base:latest"
+      command = "grep hello /mnt/volume/hello"
+    },
+  ]
+}
+        `,
+			},
+		},
+		"with checksum verification": {
+			{
+				ExpectNonEmptyPlan: true,
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_container_volume" "test" {
+  name      = "test"
+  inventory = data.imagetest_inventory.this
+  init_containers = [
+    {
+      image   = "cgr.dev/chainguard/wolfi-base:latest"
+      command = "echo hello > /mnt/volume/hello"
+    },
+  ]
+  verify_checksum = {
+    algorithm = "sha256"
+    expected = {
+      "hello" = "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"
+    }
+  }
+}
+        `,
+			},
+		},
+		"with changed init containers": {
+			{
+				ExpectNonEmptyPlan: true,
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_container_volume" "test" {
+  name      = "test"
+  inventory = data.imagetest_inventory.this
+  init_containers = [
+    {
+      image   = "cgr.dev/chainguard/wolfi-base:latest"
+      command = "echo hello > /mnt/volume/hello"
+    },
+  ]
+}
+        `,
+			},
+			{
+				ExpectNonEmptyPlan: true,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("imagetest_container_volume.test", plancheck.ResourceActionReplace),
+					},
+				},
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_container_volume" "test" {
+  name      = "test"
+  inventory = data.imagetest_inventory.this
+  init_containers = [
+    {
+      image   = "cgr.dev/chainguard/wolfi-base:latest"
+      command = "echo goodbye > /mnt/volume/hello"
+    },
+  ]
+}
+        `,
+			},
+		},
+		"with invalid checksum algorithm": {
+			{
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_container_volume" "test" {
+  name      = "test"
+  inventory = data.imagetest_inventory.this
+  verify_checksum = {
+    algorithm = "crc32"
+    expected  = {}
+  }
+}
+        `,
+				ExpectError: regexp.MustCompile(`invalid verify_checksum algorithm`),
+			},
+		},
+		"with retention policy": {
+			{
+				ExpectNonEmptyPlan: true,
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_container_volume" "test" {
+  name             = "test"
+  inventory        = data.imagetest_inventory.this
+  retention_policy = "retain_if_non_empty"
+}
+        `,
+			},
+		},
+		"with pre delete hook": {
+			{
+				ExpectNonEmptyPlan: true,
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_container_volume" "test" {
+  name      = "test"
+  inventory = data.imagetest_inventory.this
+  pre_delete_hook = {
+    image   = "cgr.dev/chainguard/wolfi-base:latest"
+    command = "ls -la /mnt/volume"
+  }
+}
+        `,
+			},
+		},
+		"with invalid retention policy": {
+			{
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_container_volume" "test" {
+  name             = "test"
+  inventory        = data.imagetest_inventory.this
+  retention_policy = "forever"
+}
+        `,
+				ExpectError: regexp.MustCompile(`invalid retention_policy`),
+			},
+		},
+		"with encryption at rest": {
+			{
+				ExpectNonEmptyPlan: true,
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_container_volume" "test" {
+  name      = "test"
+  inventory = data.imagetest_inventory.this
+}
+        `,
+				// the local driver doesn't encrypt volumes
+				Check: resource.TestCheckResourceAttr("imagetest_container_volume.test", "encryption_at_rest", "false"),
+			},
+		},
+		"with required encryption at rest": {
+			{
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_container_volume" "test" {
+  name                       = "test"
+  inventory                  = data.imagetest_inventory.this
+  require_encryption_at_rest = true
+}
+        `,
+				ExpectError: regexp.MustCompile(`volume is not encrypted at rest`),
+			},
+		},
+		"with access mode": {
+			{
+				ExpectNonEmptyPlan: true,
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_container_volume" "test" {
+  name        = "test"
+  inventory   = data.imagetest_inventory.this
+  access_mode = "ReadWriteMany"
+}
+        `,
+				Check: resource.TestCheckResourceAttr("imagetest_container_volume.test", "access_mode", "ReadWriteMany"),
+			},
+		},
+		"with invalid access mode": {
+			{
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_container_volume" "test" {
+  name        = "test"
+  inventory   = data.imagetest_inventory.this
+  access_mode = "ReadWriteSometimes"
+}
+        `,
+				ExpectError: regexp.MustCompile(`invalid access_mode`),
+			},
+		},
+		"with reclaim policy": {
+			{
+				ExpectNonEmptyPlan: true,
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_container_volume" "test" {
+  name           = "test"
+  inventory      = data.imagetest_inventory.this
+  reclaim_policy = "Delete"
+}
+        `,
+			},
+		},
+		"with invalid reclaim policy": {
+			{
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_container_volume" "test" {
+  name           = "test"
+  inventory      = data.imagetest_inventory.this
+  reclaim_policy = "Recycle"
+}
+        `,
+				ExpectError: regexp.MustCompile(`invalid reclaim_policy`),
+			},
+		},
+		"with failing init container": {
+			{
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_container_volume" "test" {
+  name      = "test"
+  inventory = data.imagetest_inventory.this
+  init_containers = [
+    {
+      image   = "cgr.dev/chainguard/wolfi-base:latest"
+      command = "exit 1"
+    },
+  ]
+}
+        `,
+				ExpectError: regexp.MustCompile(`failed to initialize volume`),
+			},
+		},
+		"with invalid cleanup strategy": {
+			{
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_container_volume" "test" {
+  name             = "test"
+  inventory        = data.imagetest_inventory.this
+  cleanup_strategy = "sometimes"
+}
+        `,
+				ExpectError: regexp.MustCompile(`invalid cleanup_strategy`),
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			resource.Test(t, resource.TestCase{
+				PreCheck:                 func() { testAccPreCheck(t) },
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps:                    tc,
+			})
+		})
+	}
+}
+
+func TestAccContainerVolumeResourceRetentionPolicy(t *testing.T) {
+	volumes := testAccRunVolumes(t)
+
 	resource.Test(t, resource.TestCase{
 		PreCheck:                 func() { testAccPreCheck(t) },
 		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: func(*terraform.State) error {
+			names, err := volumes()
+			if err != nil {
+				return err
+			}
+			if len(names) != 1 || !strings.HasPrefix(names[0], "full-") {
+				return fmt.Errorf("got volumes %v after destroy, want only the non-empty volume", names)
+			}
+			return nil
+		},
 		Steps: []resource.TestStep{
 			{
 				ExpectNonEmptyPlan: true,
 				Config: `
+provider "imagetest" {
+  container_labels_from_env = ["IMAGETEST_TEST_RUN"]
+}
+
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_container_volume" "full" {
+  name             = "full"
+  inventory        = data.imagetest_inventory.this
+  retention_policy = "retain_if_non_empty"
+  init_containers = [
+    {
+      image   = "cgr.dev/chainguard/wolfi-base:latest"
+      command = "echo hello > /mnt/volume/hello"
+    },
+  ]
+}
+
+resource "imagetest_container_volume" "empty" {
+  name             = "empty"
+  inventory        = data.imagetest_inventory.this
+  retention_policy = "retain_if_non_empty"
+}
+        `,
+			},
+		},
+	})
+}
+
+// testAccRunVolumes labels the volumes created by the test with a unique run
+// ID, through the provider container_labels_from_env, and returns a function
+// listing the names of the volumes left. They are removed once the test is
+// done.
+func testAccRunVolumes(t *testing.T) func() ([]string, error) {
+	t.Helper()
+
+	runId := uuid.NewString()
+	t.Setenv("IMAGETEST_TEST_RUN", runId)
+	runFilter := filters.NewArgs(filters.Arg("label", "terraform.imagetest/IMAGETEST_TEST_RUN="+runId))
+
+	ctx := context.Background()
+	cli, err := cprovider.NewDockerClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list := func() ([]string, error) {
+		resp, err := cli.VolumeList(ctx, volume.ListOptions{Filters: runFilter})
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, v := range resp.Volumes {
+			names = append(names, v.Name)
+		}
+		slices.Sort(names)
+		return names, nil
+	}
+
+	t.Cleanup(func() {
+		names, _ := list()
+		for _, name := range names {
+			_ = cli.RemoveVolume(ctx, name, true)
+		}
+	})
+
+	return list
+}
+
+func TestAccContainerVolumeResourcePreDeleteHook(t *testing.T) {
+	volumes := testAccRunVolumes(t)
+
+	config := func(cmd string) string {
+		return fmt.Sprintf(`
+provider "imagetest" {
+  container_labels_from_env = ["IMAGETEST_TEST_RUN"]
+}
+
 data "imagetest_inventory" "this" {}
 
 resource "imagetest_container_volume" "test" {
   name      = "test"
   inventory = data.imagetest_inventory.this
+  pre_delete_hook = {
+    image   = "cgr.dev/chainguard/wolfi-base:latest"
+    command = %q
+  }
+}
+        `, cmd)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: func(*terraform.State) error {
+			names, err := volumes()
+			if err != nil {
+				return err
+			}
+			if len(names) > 0 {
+				return fmt.Errorf("got volumes %v after destroy, want none", names)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				ExpectNonEmptyPlan: true,
+				Config:             config("echo flushing && exit 3"),
+			},
+			{
+				// a failing hook shows it ran, and keeps the volume
+				Destroy:     true,
+				Config:      config("echo flushing && exit 3"),
+				ExpectError: regexp.MustCompile(`running pre delete hook`),
+			},
+			{
+				ExpectNonEmptyPlan: true,
+				Config:             config("echo flushing"),
+				Check: func(*terraform.State) error {
+					names, err := volumes()
+					if err != nil {
+						return err
+					}
+					if len(names) != 1 {
+						return fmt.Errorf("got volumes %v after the failed hook, want the volume to be kept", names)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccContainerVolumeResourcePreDeleteHookMissingVolume(t *testing.T) {
+	volumes := testAccRunVolumes(t)
+
+	cli, err := cprovider.NewDockerClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := `
+provider "imagetest" {
+  container_labels_from_env = ["IMAGETEST_TEST_RUN"]
+}
+
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_container_volume" "test" {
+  name             = "test"
+  inventory        = data.imagetest_inventory.this
+  retention_policy = "retain_if_non_empty"
+  pre_delete_hook = {
+    image   = "cgr.dev/chainguard/wolfi-base:latest"
+    command = "exit 3"
+  }
+}
+        `
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		// the hook would fail if it ran, and recreate the volume by mounting it
+		CheckDestroy: func(*terraform.State) error {
+			names, err := volumes()
+			if err != nil {
+				return err
+			}
+			if len(names) > 0 {
+				return fmt.Errorf("got volumes %v after destroy, want none", names)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				ExpectNonEmptyPlan: true,
+				Config:             config,
+			},
+			{
+				ExpectNonEmptyPlan: true,
+				// remove the volume behind the provider's back
+				PreConfig: func() {
+					names, err := volumes()
+					if err != nil {
+						t.Fatal(err)
+					}
+					for _, name := range names {
+						if err := cli.RemoveVolume(context.Background(), name, true); err != nil {
+							t.Fatal(err)
+						}
+					}
+				},
+				Config: config,
+			},
+		},
+	})
+}
+
+func TestAccContainerVolumeResourceReclaimPolicy(t *testing.T) {
+	volumes := testAccRunVolumes(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: func(*terraform.State) error {
+			names, err := volumes()
+			if err != nil {
+				return err
+			}
+			if len(names) != 1 || !strings.HasPrefix(names[0], "retained-") {
+				return fmt.Errorf("got volumes %v after destroy, want only the retained volume", names)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				ExpectNonEmptyPlan: true,
+				Config: `
+provider "imagetest" {
+  container_labels_from_env = ["IMAGETEST_TEST_RUN"]
+}
+
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_container_volume" "retained" {
+  name           = "retained"
+  inventory      = data.imagetest_inventory.this
+  reclaim_policy = "Retain"
+}
+
+resource "imagetest_container_volume" "deleted" {
+  name           = "deleted"
+  inventory      = data.imagetest_inventory.this
+  reclaim_policy = "Delete"
 }
         `,
 			},
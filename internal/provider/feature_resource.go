@@ -8,6 +8,7 @@ import (
 	"github.com/chainguard-dev/terraform-provider-imagetest/internal/features"
 	"github.com/chainguard-dev/terraform-provider-imagetest/internal/inventory"
 	"github.com/chainguard-dev/terraform-provider-imagetest/internal/log"
+	"github.com/chainguard-dev/terraform-provider-imagetest/internal/notify"
 	itypes "github.com/chainguard-dev/terraform-provider-imagetest/internal/types"
 	"github.com/chainguard-dev/terraform-provider-imagetest/internal/util"
 	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
@@ -252,6 +253,13 @@ func (r *FeatureResource) ModifyPlan(ctx context.Context, req resource.ModifyPla
 }
 
 func (r *FeatureResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	release, diags := r.store.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
+	defer release()
+
 	ctx = log.WithCtx(ctx, r.store.Logger())
 
 	var data FeatureResourceModel
@@ -260,14 +268,18 @@ func (r *FeatureResource) Create(ctx context.Context, req resource.CreateRequest
 		return
 	}
 
-	timeout, diags := data.Timeouts.Create(ctx, defaultFeatureCreateTimeout)
+	timeout, diags := data.Timeouts.Create(ctx, r.store.Timeout(defaultFeatureCreateTimeout))
 	resp.Diagnostics.Append(diags...)
 
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	if data.Harness.Skipped.ValueBool() {
-		resp.Diagnostics.AddWarning(fmt.Sprintf("skipping feature [%s] since harness was skipped", data.Id.ValueString()), "given provider runtime labels do not match feature labels")
+		reason := "given provider runtime labels do not match feature labels"
+		if r.store.dryRun {
+			reason = "dry_run_all is set on the provider"
+		}
+		resp.Diagnostics.AddWarning(fmt.Sprintf("skipping feature [%s] since harness was skipped", data.Id.ValueString()), reason)
 		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 		return
 	}
@@ -295,6 +307,10 @@ func (r *FeatureResource) Create(ctx context.Context, req resource.CreateRequest
 				return
 			}
 
+			if err := r.store.FinishRun(ctx, data.Harness.Id.ValueString(), nil); err != nil {
+				resp.Diagnostics.AddWarning("failed to send harness notifications", err.Error())
+			}
+
 			// Destroy the harness...
 			if r.store.SkipTeardown() {
 				resp.Diagnostics.AddWarning(fmt.Sprintf("skipping harness [%s] teardown because IMAGETEST_SKIP_TEARDOWN is set", data.Harness.Id.ValueString()), "harness must be removed manually")
@@ -305,6 +321,10 @@ func (r *FeatureResource) Create(ctx context.Context, req resource.CreateRequest
 				resp.Diagnostics.AddError("failed to destroy harness", err.Error())
 				return
 			}
+
+			if err := r.store.RemoveInventoryNetwork(ctx, data.Harness.Inventory); err != nil {
+				resp.Diagnostics.AddWarning("failed to remove inventory network", err.Error())
+			}
 		}
 	}()
 
@@ -340,7 +360,9 @@ func (r *FeatureResource) Create(ctx context.Context, req resource.CreateRequest
 
 	log.Info(ctx, fmt.Sprintf("testing feature [%s (%s)] against harness [%s]", data.Name.ValueString(), data.Id.ValueString(), data.Harness.Id.ValueString()))
 
-	if err := r.test(ctx, builder.Build()); err != nil {
+	run, _ := r.store.runs.Get(data.Harness.Id.ValueString())
+	if err := r.test(ctx, builder.Build(), run); err != nil {
+		r.store.failedHarnesses.Set(data.Harness.Id.ValueString(), true)
 		resp.Diagnostics.AddError("failed to test feature", err.Error())
 		return
 	}
@@ -375,9 +397,31 @@ func (r *FeatureResource) step(harness itypes.Harness, level itypes.Level, model
 	), nil
 }
 
-func (r *FeatureResource) test(ctx context.Context, feature itypes.Feature) (err error) {
+// test runs the feature steps, recording their outcome to run when it is not
+// nil.
+func (r *FeatureResource) test(ctx context.Context, feature itypes.Feature, run *notify.Run) (err error) {
 	actions := make(map[itypes.Level][]itypes.Step)
 
+	exec := func(step itypes.Step) (context.Context, error) {
+		start := time.Now()
+		c, e := step.Fn()(ctx)
+		if run != nil {
+			output := itypes.StepOutput(c)
+			if e != nil {
+				output = e.Error()
+			}
+			run.RecordStep(notify.StepResult{
+				Feature:  feature.Name(),
+				Step:     step.Name(),
+				Start:    start,
+				Duration: time.Since(start),
+				Output:   output,
+				Err:      e,
+			})
+		}
+		return c, e
+	}
+
 	for _, s := range feature.Steps() {
 		actions[s.Level()] = append(actions[s.Level()], s)
 	}
@@ -391,7 +435,7 @@ func (r *FeatureResource) test(ctx context.Context, feature itypes.Feature) (err
 
 	afters := func() {
 		for _, after := range actions[itypes.After] {
-			c, e := after.Fn()(ctx)
+			c, e := exec(after)
 			if e != nil {
 				err = wraperr(fmt.Errorf("during after step: %v", e))
 			}
@@ -401,7 +445,7 @@ func (r *FeatureResource) test(ctx context.Context, feature itypes.Feature) (err
 	defer afters()
 
 	for _, before := range actions[itypes.Before] {
-		c, e := before.Fn()(ctx)
+		c, e := exec(before)
 		if e != nil {
 			return wraperr(fmt.Errorf("during before step: %v", e))
 		}
@@ -409,7 +453,7 @@ func (r *FeatureResource) test(ctx context.Context, feature itypes.Feature) (err
 	}
 
 	for _, assessment := range actions[itypes.Assessment] {
-		c, e := assessment.Fn()(ctx)
+		c, e := exec(assessment)
 		if e != nil {
 			return wraperr(fmt.Errorf("during assessment step: %v", e))
 		}
@@ -423,6 +467,13 @@ func (r *FeatureResource) Read(_ context.Context, _ resource.ReadRequest, _ *res
 }
 
 func (r *FeatureResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	release, diags := r.store.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
+	defer release()
+
 	var data FeatureResourceModel
 
 	// Read Terraform plan data into the model
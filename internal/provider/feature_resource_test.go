@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
@@ -116,3 +117,40 @@ resource "imagetest_feature" "test" {
 		},
 	})
 }
+
+func TestAccFeatureResourceProviderTimeout(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				ExpectNonEmptyPlan: true,
+				ExpectError:        regexp.MustCompile("failed to test feature"),
+				Config: `
+provider "imagetest" {
+  resource_timeout_default = "5s"
+}
+
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_harness_container" "test" {
+  name = "test"
+  inventory = data.imagetest_inventory.this
+}
+
+resource "imagetest_feature" "test" {
+  name = "Timeout"
+  description = "Test that the provider default timeout applies"
+  harness = imagetest_harness_container.test
+  steps = [
+    {
+      name = "Sleep"
+      cmd = "sleep 30"
+    },
+  ]
+}
+        `,
+			},
+		},
+	})
+}
@@ -2,7 +2,9 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/chainguard-dev/terraform-provider-imagetest/internal/inventory"
 	"github.com/chainguard-dev/terraform-provider-imagetest/internal/log"
@@ -28,8 +30,16 @@ type FeatureHarnessResourceModel struct {
 }
 
 type FeatureHarnessVolumeMountModel struct {
-	Source      ContainerVolumeResourceModel `tfsdk:"source"`
-	Destination string                       `tfsdk:"destination"`
+	Source      FeatureHarnessVolumeSourceModel `tfsdk:"source"`
+	Destination string                          `tfsdk:"destination"`
+}
+
+// FeatureHarnessVolumeSourceModel is the subset of an imagetest_container_volume
+// resource that harnesses receive to mount the volume.
+type FeatureHarnessVolumeSourceModel struct {
+	Id        types.String             `tfsdk:"id"`
+	Name      types.String             `tfsdk:"name"`
+	Inventory InventoryDataSourceModel `tfsdk:"inventory"`
 }
 
 type RegistryResourceAuthModel struct {
@@ -152,6 +162,62 @@ func (r *HarnessResource) ShouldSkip(ctx context.Context, req resource.CreateReq
 	return skip
 }
 
+// DryRun returns true if the provider is running in dry run mode, in which
+// case the harness must be skipped before making any changes to the Docker
+// daemon.
+func (r *HarnessResource) DryRun(resp *resource.CreateResponse, id string) bool {
+	if !r.store.dryRun {
+		return false
+	}
+
+	resp.Diagnostics.AddWarning(
+		fmt.Sprintf("skipping harness [%s] creation", id),
+		"dry_run_all is set on the provider")
+
+	return true
+}
+
+// Teardown finishes the run of a harness its features did not tear down,
+// because it has no features or its setup failed, and removes what is left of
+// it: the harness container, its inventory entry and the inventory network
+// once no harnesses remain in it. It must not be called for skipped harnesses.
+func (r *HarnessResource) Teardown(ctx context.Context, inv InventoryDataSourceModel, id string) error {
+	// nothing was created for the harness in dry run mode
+	if r.store.dryRun {
+		return nil
+	}
+
+	var errs []error
+	if err := r.store.FinishRun(ctx, id, nil); err != nil {
+		errs = append(errs, fmt.Errorf("sending harness notifications: %w", err))
+	}
+
+	if r.store.SkipTeardown() {
+		return errors.Join(errs...)
+	}
+
+	if err := r.store.cli.RemoveContainer(ctx, id); err != nil {
+		errs = append(errs, err)
+	}
+
+	harnesses, err := r.store.Inventory(inv).GetHarnesses(ctx)
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+
+	if slices.Contains(harnesses, inventory.Harness(id)) {
+		if err := r.store.Inventory(inv).RemoveHarness(ctx, inventory.Harness(id)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := r.store.RemoveInventoryNetwork(ctx, inv); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
 // AddHarnessSchemaAttributes adds common attributes to the given map. values
 // provided in attrs will override any specified defaults.
 func addHarnessResourceSchemaAttributes() map[string]schema.Attribute {
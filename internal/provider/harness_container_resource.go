@@ -76,6 +76,13 @@ func (r *HarnessContainerResource) Schema(_ context.Context, _ resource.SchemaRe
 }
 
 func (r *HarnessContainerResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	release, diags := r.store.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
+	defer release()
+
 	ctx = log.WithCtx(ctx, r.store.Logger())
 
 	var data HarnessContainerResourceModel
@@ -154,6 +161,17 @@ func (r *HarnessContainerResource) Create(ctx context.Context, req resource.Crea
 		cfg.Networks = append(cfg.Networks, network.Name.ValueString())
 	}
 
+	if len(networks) == 0 {
+		invnet, err := r.store.InventoryNetwork(data.Inventory)
+		if err != nil {
+			resp.Diagnostics.AddError("failed to encode inventory network name", err.Error())
+			return
+		}
+		cfg.DefaultNetwork = invnet
+	}
+
+	cfg.VolumeRemover = r.store.volumeRemover(data.Id.ValueString())
+
 	if data.Volumes != nil {
 		for _, vol := range data.Volumes {
 			cfg.ManagedVolumes = append(cfg.ManagedVolumes, container.ConfigMount{
@@ -172,6 +190,12 @@ func (r *HarnessContainerResource) Create(ctx context.Context, req resource.Crea
 		cfg.Env[k] = v
 	}
 
+	if r.DryRun(resp, data.Id.ValueString()) {
+		data.Skipped = types.BoolValue(true)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
 	harness, err := container.New(data.Id.ValueString(), r.store.cli, cfg)
 	if err != nil {
 		resp.Diagnostics.AddError("invalid provider data", "...")
@@ -185,6 +209,9 @@ func (r *HarnessContainerResource) Create(ctx context.Context, req resource.Crea
 	// TODO: Change this signature
 	if _, err := harness.Setup()(ctx); err != nil {
 		resp.Diagnostics.AddError("failed to setup harness", err.Error())
+		if err := r.Teardown(ctx, data.Inventory, data.Id.ValueString()); err != nil {
+			resp.Diagnostics.AddWarning("failed to tear down harness", err.Error())
+		}
 		return
 	}
 
@@ -207,6 +234,13 @@ func (r *HarnessContainerResource) Read(ctx context.Context, req resource.ReadRe
 }
 
 func (r *HarnessContainerResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	release, diags := r.store.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
+	defer release()
+
 	var data HarnessContainerResourceModel
 
 	// Read Terraform plan data into the model
@@ -221,6 +255,13 @@ func (r *HarnessContainerResource) Update(ctx context.Context, req resource.Upda
 }
 
 func (r *HarnessContainerResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	release, diags := r.store.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
+	defer release()
+
 	var data HarnessContainerResourceModel
 
 	// Read Terraform prior state data into the model
@@ -229,6 +270,14 @@ func (r *HarnessContainerResource) Delete(ctx context.Context, req resource.Dele
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	if data.Skipped.ValueBool() {
+		return
+	}
+
+	if err := r.Teardown(ctx, data.Inventory, data.Id.ValueString()); err != nil {
+		resp.Diagnostics.AddWarning("failed to tear down harness", err.Error())
+	}
 }
 
 func (r *HarnessContainerResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
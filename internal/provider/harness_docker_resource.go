@@ -3,12 +3,18 @@ package provider
 import (
 	"context"
 	"fmt"
+	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/chainguard-dev/terraform-provider-imagetest/internal/containers/provider"
 	"github.com/chainguard-dev/terraform-provider-imagetest/internal/harnesses/container"
 	"github.com/chainguard-dev/terraform-provider-imagetest/internal/harnesses/docker"
+	"github.com/chainguard-dev/terraform-provider-imagetest/internal/inventory"
 	"github.com/chainguard-dev/terraform-provider-imagetest/internal/log"
+	"github.com/chainguard-dev/terraform-provider-imagetest/internal/notify"
 	"github.com/chainguard-dev/terraform-provider-imagetest/internal/util"
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/volume"
@@ -17,8 +23,10 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	k8sresource "k8s.io/apimachinery/pkg/api/resource"
 )
 
 const (
@@ -50,12 +58,89 @@ type HarnessDockerResourceModel struct {
 	Skipped   types.Bool                       `tfsdk:"skipped"`
 	Volumes   []FeatureHarnessVolumeMountModel `tfsdk:"volumes"`
 
-	Image      types.String                             `tfsdk:"image"`
-	Privileged types.Bool                               `tfsdk:"privileged"`
-	Envs       types.Map                                `tfsdk:"envs"`
-	Mounts     []ContainerResourceMountModel            `tfsdk:"mounts"`
-	Networks   map[string]ContainerResourceModelNetwork `tfsdk:"networks"`
-	Registries map[string]DockerRegistryResourceModel   `tfsdk:"registries"`
+	Image                  types.String                             `tfsdk:"image"`
+	Privileged             types.Bool                               `tfsdk:"privileged"`
+	Envs                   types.Map                                `tfsdk:"envs"`
+	Mounts                 []ContainerResourceMountModel            `tfsdk:"mounts"`
+	Networks               map[string]ContainerResourceModelNetwork `tfsdk:"networks"`
+	Registries             map[string]DockerRegistryResourceModel   `tfsdk:"registries"`
+	RunAsNonRoot           types.Bool                               `tfsdk:"run_as_nonroot"`
+	ReadOnlyRootFilesystem types.Bool                               `tfsdk:"read_only_root_filesystem"`
+	DropAllCapabilities    types.Bool                               `tfsdk:"drop_all_capabilities"`
+	CapAdd                 []string                                 `tfsdk:"cap_add"`
+	SeccompProfile         types.String                             `tfsdk:"seccomp_profile"`
+	AppArmorProfile        types.String                             `tfsdk:"apparmor_profile"`
+	NetworkEgressFilter    *HarnessDockerEgressFilterModel          `tfsdk:"network_egress_filter"`
+	ResourceBudget         *HarnessDockerResourceBudgetModel        `tfsdk:"resource_budget"`
+	ConcurrentStepLimit    types.Int64                              `tfsdk:"concurrent_step_limit"`
+	StepImageCache         types.Bool                               `tfsdk:"step_image_cache"`
+	TraceId                types.String                             `tfsdk:"trace_id"`
+	SlackNotification      *HarnessDockerSlackNotificationModel     `tfsdk:"slack_notification"`
+	PagerdutyIntegration   *HarnessDockerPagerdutyIntegrationModel  `tfsdk:"pagerduty_integration"`
+	GithubStatus           *HarnessDockerGithubStatusModel          `tfsdk:"github_status"`
+	DatadogMetrics         *HarnessDockerDatadogMetricsModel        `tfsdk:"datadog_metrics"`
+	ReportToTestmo         *HarnessDockerReportToTestmoModel        `tfsdk:"report_to_testmo"`
+	TestmoRunUrl           types.String                             `tfsdk:"testmo_run_url"`
+	ReportToAllure         *HarnessDockerReportToAllureModel        `tfsdk:"report_to_allure"`
+	ReportToJira           *HarnessDockerReportToJiraModel          `tfsdk:"report_to_jira"`
+	XrayExecutionKey       types.String                             `tfsdk:"xray_execution_key"`
+}
+
+type HarnessDockerSlackNotificationModel struct {
+	WebhookUrl       types.String `tfsdk:"webhook_url"`
+	Channel          types.String `tfsdk:"channel"`
+	MentionOnFailure []string     `tfsdk:"mention_on_failure"`
+}
+
+type HarnessDockerPagerdutyIntegrationModel struct {
+	IntegrationKey types.String `tfsdk:"integration_key"`
+	Severity       types.String `tfsdk:"severity"`
+	DedupKey       types.String `tfsdk:"dedup_key"`
+}
+
+type HarnessDockerGithubStatusModel struct {
+	Token   types.String `tfsdk:"token"`
+	Repo    types.String `tfsdk:"repo"`
+	Sha     types.String `tfsdk:"sha"`
+	Context types.String `tfsdk:"context"`
+}
+
+type HarnessDockerDatadogMetricsModel struct {
+	ApiKey types.String `tfsdk:"api_key"`
+	AppKey types.String `tfsdk:"app_key"`
+	Tags   []string     `tfsdk:"tags"`
+}
+
+type HarnessDockerReportToTestmoModel struct {
+	Url       types.String `tfsdk:"url"`
+	Token     types.String `tfsdk:"token"`
+	ProjectId types.Int64  `tfsdk:"project_id"`
+	Milestone types.String `tfsdk:"milestone"`
+}
+
+type HarnessDockerReportToAllureModel struct {
+	AllureServerUrl types.String `tfsdk:"allure_server_url"`
+	ProjectId       types.Int64  `tfsdk:"project_id"`
+	Token           types.String `tfsdk:"token"`
+}
+
+type HarnessDockerReportToJiraModel struct {
+	JiraUrl      types.String `tfsdk:"jira_url"`
+	ClientId     types.String `tfsdk:"client_id"`
+	ClientSecret types.String `tfsdk:"client_secret"`
+	ProjectKey   types.String `tfsdk:"project_key"`
+	TestPlanKey  types.String `tfsdk:"test_plan_key"`
+}
+
+type HarnessDockerResourceBudgetModel struct {
+	TotalMemory     types.String `tfsdk:"total_memory"`
+	TotalCpuSeconds types.Int64  `tfsdk:"total_cpu_seconds"`
+	TotalWallTime   types.String `tfsdk:"total_wall_time"`
+}
+
+type HarnessDockerEgressFilterModel struct {
+	AllowedDomains []string `tfsdk:"allowed_domains"`
+	AllowedIPs     []string `tfsdk:"allowed_ips"`
 }
 
 type DockerRegistryResourceModel struct {
@@ -78,6 +163,13 @@ func (r *HarnessDockerResource) Schema(_ context.Context, _ resource.SchemaReque
 }
 
 func (r *HarnessDockerResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	release, diags := r.store.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
+	defer release()
+
 	var data HarnessDockerResourceModel
 	var opts []docker.Option
 
@@ -93,6 +185,8 @@ func (r *HarnessDockerResource) Create(ctx context.Context, req resource.CreateR
 		return
 	}
 	data.Skipped = types.BoolValue(skip)
+	data.TestmoRunUrl = types.StringNull()
+	data.XrayExecutionKey = types.StringNull()
 
 	if data.Skipped.ValueBool() {
 		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -104,7 +198,61 @@ func (r *HarnessDockerResource) Create(ctx context.Context, req resource.CreateR
 		resp.Diagnostics.AddError("invalid resource input", fmt.Sprintf("invalid image reference: %s", err))
 		return
 	}
-	opts = append(opts, docker.WithImageRef(ref))
+	opts = append(opts,
+		docker.WithImageRef(ref),
+		docker.WithRunAsNonRoot(data.RunAsNonRoot.ValueBool()),
+		docker.WithReadOnlyRootfs(data.ReadOnlyRootFilesystem.ValueBool()),
+		docker.WithCapabilities(data.DropAllCapabilities.ValueBool(), data.CapAdd...),
+		docker.WithSeccompProfile(data.SeccompProfile.ValueString()),
+		docker.WithConcurrentStepLimit(data.ConcurrentStepLimit.ValueInt64()))
+
+	if data.StepImageCache.ValueBool() {
+		opts = append(opts, docker.WithImageCache(&r.store.pulledImages))
+	}
+
+	if !data.TraceId.IsNull() {
+		opts = append(opts, docker.WithTraceParent(data.TraceId.ValueString()))
+	}
+
+	if !data.AppArmorProfile.IsNull() {
+		opts = append(opts, docker.WithAppArmorProfile(data.AppArmorProfile.ValueString()))
+	}
+
+	if f := data.NetworkEgressFilter; f != nil {
+		opts = append(opts, docker.WithEgressFilter(f.AllowedDomains, f.AllowedIPs))
+	}
+
+	if b := data.ResourceBudget; b != nil {
+		budget := docker.ResourceBudgetOpt{
+			TotalCPU: time.Duration(b.TotalCpuSeconds.ValueInt64()) * time.Second,
+		}
+
+		if !b.TotalMemory.IsNull() {
+			q, err := k8sresource.ParseQuantity(b.TotalMemory.ValueString())
+			if err != nil {
+				resp.Diagnostics.AddError("invalid resource input", fmt.Sprintf("invalid resource budget total_memory: %s", err))
+				return
+			}
+			budget.TotalMemory = q.Value()
+		}
+
+		if !b.TotalWallTime.IsNull() {
+			d, err := time.ParseDuration(b.TotalWallTime.ValueString())
+			if err != nil {
+				resp.Diagnostics.AddError("invalid resource input", fmt.Sprintf("invalid resource budget total_wall_time: %s", err))
+				return
+			}
+			budget.TotalWallTime = d
+		}
+
+		opts = append(opts, docker.WithResourceBudget(budget))
+	}
+
+	notifiers, err := data.notifiers()
+	if err != nil {
+		resp.Diagnostics.AddError("invalid resource input", err.Error())
+		return
+	}
 
 	if r.store.providerResourceData.Harnesses != nil &&
 		r.store.providerResourceData.Harnesses.Docker != nil &&
@@ -180,6 +328,17 @@ func (r *HarnessDockerResource) Create(ctx context.Context, req resource.CreateR
 		opts = append(opts, docker.WithNetworks(network.Name.ValueString()))
 	}
 
+	if len(networks) == 0 {
+		invnet, err := r.store.InventoryNetwork(data.Inventory)
+		if err != nil {
+			resp.Diagnostics.AddError("failed to encode inventory network name", err.Error())
+			return
+		}
+		opts = append(opts, docker.WithDefaultNetwork(invnet))
+	}
+
+	opts = append(opts, docker.WithVolumeRemover(r.store.volumeRemover(data.Id.ValueString())))
+
 	if data.Volumes != nil {
 		for _, vol := range data.Volumes {
 			opts = append(opts, docker.WithManagedVolumes(container.ConfigMount{
@@ -196,11 +355,23 @@ func (r *HarnessDockerResource) Create(ctx context.Context, req resource.CreateR
 	}
 	opts = append(opts, docker.WithEnvs(envs))
 
+	// validate the options before anything is created for the harness
+	if err := docker.ValidateOptions(opts...); err != nil {
+		resp.Diagnostics.AddError("invalid resource input", err.Error())
+		return
+	}
+
 	id := data.Id.ValueString()
+	if r.DryRun(resp, id) {
+		data.Skipped = types.BoolValue(true)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
 	configVolumeName := id + "-config"
 
 	_, err = r.store.cli.VolumeCreate(ctx, volume.CreateOptions{
-		Labels: provider.DefaultLabels,
+		Labels: r.store.cli.Labels(),
 		Name:   configVolumeName,
 	})
 	if err != nil {
@@ -212,24 +383,237 @@ func (r *HarnessDockerResource) Create(ctx context.Context, req resource.CreateR
 
 	harness, err := docker.New(id, r.store.cli, opts...)
 	if err != nil {
-		resp.Diagnostics.AddError("invalid provider data", err.Error())
+		resp.Diagnostics.AddError("failed to create docker harness", err.Error())
+		if err := r.store.cli.RemoveVolume(ctx, configVolumeName, false); err != nil {
+			resp.Diagnostics.AddWarning("failed to remove config volume for the Docker harness", err.Error())
+		}
 		return
 	}
 	r.store.harnesses.Set(id, harness)
 
+	run := notify.NewRun(data.Name.ValueString(), notifiers...)
+	r.store.runs.Set(id, run)
+	if err := run.Start(ctx); err != nil {
+		resp.Diagnostics.AddWarning("failed to send harness notifications", err.Error())
+	}
+
+	for _, n := range notifiers {
+		switch n := n.(type) {
+		case *notify.Testmo:
+			if n.RunURL() != "" {
+				data.TestmoRunUrl = types.StringValue(n.RunURL())
+			}
+		case *notify.Xray:
+			if n.ExecutionKey() != "" {
+				data.XrayExecutionKey = types.StringValue(n.ExecutionKey())
+				log.Info(ctx, fmt.Sprintf("created Xray test execution %s/browse/%s", strings.TrimSuffix(data.ReportToJira.JiraUrl.ValueString(), "/"), n.ExecutionKey()))
+			}
+		}
+	}
+
 	log.Info(ctx, fmt.Sprintf("creating container harness [%s]", id))
 
 	// Finally, create the harness
 	// TODO: Change this signature
 	if _, err := harness.Setup()(ctx); err != nil {
 		resp.Diagnostics.AddError("failed to setup harness", err.Error())
+		if err := r.store.FinishRun(ctx, id, err); err != nil {
+			resp.Diagnostics.AddWarning("failed to send harness notifications", err.Error())
+		}
+		if err := r.Teardown(ctx, data.Inventory, id); err != nil {
+			resp.Diagnostics.AddWarning("failed to tear down harness", err.Error())
+		}
 		return
 	}
 
+	// the run is finished by the last feature done with the harness, so a
+	// harness without features has nothing left to wait for
+	feats, err := r.store.Inventory(data.Inventory).GetFeatures(ctx, inventory.Harness(id))
+	if err != nil {
+		resp.Diagnostics.AddWarning("failed to get features from harness", err.Error())
+	} else if len(feats) == 0 {
+		if err := r.store.FinishRun(ctx, id, nil); err != nil {
+			resp.Diagnostics.AddWarning("failed to send harness notifications", err.Error())
+		}
+	}
+
 	// Save data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
+// notifiers returns the notifiers of the harness run.
+func (m HarnessDockerResourceModel) notifiers() ([]notify.Notifier, error) {
+	var notifiers []notify.Notifier
+
+	if s := m.SlackNotification; s != nil {
+		notifiers = append(notifiers, &notify.Slack{
+			WebhookURL: s.WebhookUrl.ValueString(),
+			Channel:    s.Channel.ValueString(),
+			Mentions:   s.MentionOnFailure,
+		})
+	}
+
+	if p := m.PagerdutyIntegration; p != nil {
+		if !slices.Contains(notify.PagerDutySeverities, p.Severity.ValueString()) {
+			return nil, fmt.Errorf("invalid pagerduty_integration severity %q, must be one of: %s", p.Severity.ValueString(), strings.Join(notify.PagerDutySeverities, ", "))
+		}
+		notifiers = append(notifiers, &notify.PagerDuty{
+			IntegrationKey: p.IntegrationKey.ValueString(),
+			Severity:       p.Severity.ValueString(),
+			DedupKey:       p.DedupKey.ValueString(),
+		})
+	}
+
+	if g := m.GithubStatus; g != nil {
+		status := &notify.GitHubStatus{
+			Token:   g.Token.ValueString(),
+			Repo:    g.Repo.ValueString(),
+			Sha:     g.Sha.ValueString(),
+			Context: g.Context.ValueString(),
+		}
+		if g.Token.IsNull() {
+			status.Token = os.Getenv("GITHUB_TOKEN")
+		}
+		if g.Repo.IsNull() {
+			status.Repo = os.Getenv("GITHUB_REPOSITORY")
+		}
+		if g.Sha.IsNull() {
+			status.Sha = os.Getenv("GITHUB_SHA")
+		}
+
+		if status.Token == "" || status.Repo == "" || status.Sha == "" {
+			return nil, fmt.Errorf("github_status requires a token, repo and sha, either set or from the GITHUB_TOKEN, GITHUB_REPOSITORY and GITHUB_SHA environment variables")
+		}
+		notifiers = append(notifiers, status)
+	}
+
+	if d := m.DatadogMetrics; d != nil {
+		notifiers = append(notifiers, &notify.Datadog{
+			APIKey: d.ApiKey.ValueString(),
+			AppKey: d.AppKey.ValueString(),
+			Tags:   d.Tags,
+		})
+	}
+
+	if t := m.ReportToTestmo; t != nil {
+		notifiers = append(notifiers, &notify.Testmo{
+			URL:       t.Url.ValueString(),
+			Token:     t.Token.ValueString(),
+			ProjectID: t.ProjectId.ValueInt64(),
+			Milestone: t.Milestone.ValueString(),
+		})
+	}
+
+	if a := m.ReportToAllure; a != nil {
+		notifiers = append(notifiers, &notify.Allure{
+			URL:       a.AllureServerUrl.ValueString(),
+			ProjectID: a.ProjectId.ValueInt64(),
+			Token:     a.Token.ValueString(),
+		})
+	}
+
+	if j := m.ReportToJira; j != nil {
+		notifiers = append(notifiers, &notify.Xray{
+			ClientID:     j.ClientId.ValueString(),
+			ClientSecret: j.ClientSecret.ValueString(),
+			ProjectKey:   j.ProjectKey.ValueString(),
+			TestPlanKey:  j.TestPlanKey.ValueString(),
+		})
+	}
+
+	return notifiers, nil
+}
+
+// ModifyPlan adds plan time warnings for the security settings of the harness
+// on top of the common harness plan modifications.
+func (r *HarnessDockerResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	r.HarnessResource.ModifyPlan(ctx, req, resp)
+	if resp.Diagnostics.HasError() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	r.warnRunAsNonRoot(ctx, req, resp)
+	r.warnDropAllCapabilities(ctx, req, resp)
+	r.warnNetworkEgressFilter(ctx, req, resp)
+}
+
+// warnRunAsNonRoot warns when steps are forced to run as a non-root user but
+// the harness image is configured to run as root, since the image may rely on
+// it.
+func (r *HarnessDockerResource) warnRunAsNonRoot(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	var runAsNonRoot types.Bool
+	if diags := req.Plan.GetAttribute(ctx, path.Root("run_as_nonroot"), &runAsNonRoot); diags.HasError() {
+		return
+	}
+
+	if !runAsNonRoot.ValueBool() {
+		return
+	}
+
+	var image types.String
+	if diags := req.Plan.GetAttribute(ctx, path.Root("image"), &image); diags.HasError() || image.IsUnknown() {
+		return
+	}
+
+	// only images that are already present in the daemon can be checked
+	// without pulling them at plan time
+	inspect, _, err := r.store.cli.ImageInspectWithRaw(ctx, image.ValueString())
+	if err != nil || inspect.Config == nil {
+		return
+	}
+
+	switch inspect.Config.User {
+	case "", "root", "0", "0:0", "root:root":
+		resp.Diagnostics.AddWarning(
+			fmt.Sprintf("harness image [%s] runs as root", image.ValueString()),
+			fmt.Sprintf("run_as_nonroot is set, so steps will run as user %s instead and may fail if they require root, e.g. to access the Docker socket", docker.NonRootUser))
+	}
+}
+
+// warnDropAllCapabilities lists the capabilities steps are left with when all
+// capabilities are dropped.
+func (r *HarnessDockerResource) warnDropAllCapabilities(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	var dropAll types.Bool
+	if diags := req.Plan.GetAttribute(ctx, path.Root("drop_all_capabilities"), &dropAll); diags.HasError() {
+		return
+	}
+
+	if !dropAll.ValueBool() {
+		return
+	}
+
+	var capAdd []string
+	if diags := req.Plan.GetAttribute(ctx, path.Root("cap_add"), &capAdd); diags.HasError() {
+		return
+	}
+
+	requested := "none"
+	if len(capAdd) > 0 {
+		requested = strings.Join(capAdd, ", ")
+	}
+
+	resp.Diagnostics.AddWarning(
+		"all capabilities are dropped for the harness steps",
+		fmt.Sprintf("drop_all_capabilities is set, steps will only be granted the capabilities listed in cap_add: %s", requested))
+}
+
+// warnNetworkEgressFilter warns that the egress filter relies on granting
+// NET_ADMIN to a setup container.
+func (r *HarnessDockerResource) warnNetworkEgressFilter(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	var filter types.Object
+	if diags := req.Plan.GetAttribute(ctx, path.Root("network_egress_filter"), &filter); diags.HasError() {
+		return
+	}
+
+	if filter.IsNull() {
+		return
+	}
+
+	resp.Diagnostics.AddWarning(
+		"network_egress_filter requires the NET_ADMIN capability",
+		"the egress filter is installed by a setup container that is granted NET_ADMIN in the harness network namespace, which the Docker daemon must allow")
+}
+
 func (r *HarnessDockerResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 	var data HarnessDockerResourceModel
 
@@ -245,6 +629,13 @@ func (r *HarnessDockerResource) Read(ctx context.Context, req resource.ReadReque
 }
 
 func (r *HarnessDockerResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	release, diags := r.store.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
+	defer release()
+
 	var data HarnessDockerResourceModel
 
 	// Read Terraform plan data into the model
@@ -259,6 +650,13 @@ func (r *HarnessDockerResource) Update(ctx context.Context, req resource.UpdateR
 }
 
 func (r *HarnessDockerResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	release, diags := r.store.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
+	defer release()
+
 	var data HarnessDockerResourceModel
 
 	// Read Terraform prior state data into the model
@@ -267,6 +665,14 @@ func (r *HarnessDockerResource) Delete(ctx context.Context, req resource.DeleteR
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	if data.Skipped.ValueBool() {
+		return
+	}
+
+	if err := r.Teardown(ctx, data.Inventory, data.Id.ValueString()); err != nil {
+		resp.Diagnostics.AddWarning("failed to tear down harness", err.Error())
+	}
 }
 
 func (r *HarnessDockerResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
@@ -286,6 +692,254 @@ func addDockerResourceSchemaAttributes() map[string]schema.Attribute {
 			Computed: true,
 			Default:  booldefault.StaticBool(false),
 		},
+		"run_as_nonroot": schema.BoolAttribute{
+			Description: "When true, steps are run as the non-root user 65534 (nobody) instead of the harness container user.",
+			Optional:    true,
+			Computed:    true,
+			Default:     booldefault.StaticBool(false),
+		},
+		"read_only_root_filesystem": schema.BoolAttribute{
+			Description: "When true, the harness container's root filesystem is mounted as read only. A tmpfs is mounted at /tmp for temporary writes.",
+			Optional:    true,
+			Computed:    true,
+			Default:     booldefault.StaticBool(false),
+		},
+		"drop_all_capabilities": schema.BoolAttribute{
+			Description: "When true, all Linux capabilities are dropped from the harness container. Capabilities the steps need must be added back with cap_add.",
+			Optional:    true,
+			Computed:    true,
+			Default:     booldefault.StaticBool(false),
+		},
+		"cap_add": schema.ListAttribute{
+			Description: "A list of Linux capabilities to add to the harness container, e.g. NET_ADMIN.",
+			Optional:    true,
+			ElementType: types.StringType,
+		},
+		"seccomp_profile": schema.StringAttribute{
+			Description: "The seccomp profile to apply to the harness container. One of \"default\" to use the Docker daemon's default profile, \"unconfined\", or the path to a seccomp JSON profile.",
+			Optional:    true,
+			Computed:    true,
+			Default:     stringdefault.StaticString("default"),
+		},
+		"apparmor_profile": schema.StringAttribute{
+			Description: "The AppArmor profile to apply to the harness container. One of \"runtime/default\" for the Docker daemon's default profile (docker-default), \"unconfined\", or the name of a profile loaded on the Docker host. Defaults to the Docker daemon's default profile.",
+			Optional:    true,
+		},
+		"network_egress_filter": schema.SingleNestedAttribute{
+			Description: "When set, outbound traffic from the harness container is dropped unless it is destined to one of the allowed domains or CIDR ranges. Containers started by the steps through the Docker daemon are not filtered.",
+			Optional:    true,
+			Attributes: map[string]schema.Attribute{
+				"allowed_domains": schema.ListAttribute{
+					Description: "DNS names outbound traffic is allowed to. These are resolved once when the harness is created.",
+					Optional:    true,
+					ElementType: types.StringType,
+				},
+				"allowed_ips": schema.ListAttribute{
+					Description: "CIDR ranges outbound traffic is allowed to, e.g. 10.0.0.0/8.",
+					Optional:    true,
+					ElementType: types.StringType,
+				},
+			},
+		},
+		"resource_budget": schema.SingleNestedAttribute{
+			Description: "The total resources the steps of this harness may consume. A step is stopped and fails as soon as any budget is exceeded.",
+			Optional:    true,
+			Attributes: map[string]schema.Attribute{
+				"total_memory": schema.StringAttribute{
+					Description: "The maximum memory usage of the harness container, e.g. 2Gi.",
+					Optional:    true,
+				},
+				"total_cpu_seconds": schema.Int64Attribute{
+					Description: "The maximum CPU time, in seconds, consumed by the harness container across all steps.",
+					Optional:    true,
+				},
+				"total_wall_time": schema.StringAttribute{
+					Description: "The maximum time steps may run for since the harness was created, e.g. 10m.",
+					Optional:    true,
+				},
+			},
+		},
+		"concurrent_step_limit": schema.Int64Attribute{
+			Description: "The maximum number of steps that run in the harness at the same time. Steps of different features using this harness run concurrently. Set to 0 for no limit.",
+			Optional:    true,
+			Computed:    true,
+			Default:     int64default.StaticInt64(4),
+		},
+		"step_image_cache": schema.BoolAttribute{
+			Description: "When true, images that were already pulled by a harness during this run are not pulled again.",
+			Optional:    true,
+			Computed:    true,
+			Default:     booldefault.StaticBool(true),
+		},
+		"trace_id": schema.StringAttribute{
+			Description: "A W3C traceparent to correlate the harness with. When set, a child span is created for the harness and its traceparent is exposed to the steps through the TRACEPARENT environment variable. The span is exported over OTLP/HTTP to the endpoint set by the standard OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT environment variables; without one, no span is recorded and the steps receive the given traceparent.",
+			Optional:    true,
+		},
+		"slack_notification": schema.SingleNestedAttribute{
+			Description: "Posts a message to Slack when the harness or any of its steps fail.",
+			Optional:    true,
+			Attributes: map[string]schema.Attribute{
+				"webhook_url": schema.StringAttribute{
+					Description: "The Slack incoming webhook URL to post the message to.",
+					Required:    true,
+					Sensitive:   true,
+				},
+				"channel": schema.StringAttribute{
+					Description: "The channel to post the message to. Defaults to the channel of the webhook.",
+					Optional:    true,
+				},
+				"mention_on_failure": schema.ListAttribute{
+					Description: "The IDs of the Slack users to mention in the message.",
+					Optional:    true,
+					ElementType: types.StringType,
+				},
+			},
+		},
+		"pagerduty_integration": schema.SingleNestedAttribute{
+			Description: "Triggers a PagerDuty event when the harness or any of its steps fail, and resolves it once the harness passes when dedup_key is set.",
+			Optional:    true,
+			Attributes: map[string]schema.Attribute{
+				"integration_key": schema.StringAttribute{
+					Description: "The integration key of the PagerDuty Events API v2 integration.",
+					Required:    true,
+					Sensitive:   true,
+				},
+				"severity": schema.StringAttribute{
+					Description: "The severity of the event, one of: critical, error, warning, info.",
+					Optional:    true,
+					Computed:    true,
+					Default:     stringdefault.StaticString("error"),
+				},
+				"dedup_key": schema.StringAttribute{
+					Description: "The key used to deduplicate events. A passing harness resolves the event triggered with the same key by an earlier failing run. Defaults to a key generated by PagerDuty, in which case events are never resolved.",
+					Optional:    true,
+				},
+			},
+		},
+		"github_status": schema.SingleNestedAttribute{
+			Description: "Reports the harness as a GitHub commit status: pending when it is created, then success or failure once every feature is done.",
+			Optional:    true,
+			Attributes: map[string]schema.Attribute{
+				"token": schema.StringAttribute{
+					Description: "The GitHub token used to create the status. Defaults to the GITHUB_TOKEN environment variable.",
+					Optional:    true,
+					Sensitive:   true,
+				},
+				"repo": schema.StringAttribute{
+					Description: "The repository of the commit, in the owner/name form. Defaults to the GITHUB_REPOSITORY environment variable.",
+					Optional:    true,
+				},
+				"sha": schema.StringAttribute{
+					Description: "The commit SHA to report the status on. Defaults to the GITHUB_SHA environment variable.",
+					Optional:    true,
+				},
+				"context": schema.StringAttribute{
+					Description: "The label that identifies the status on the commit.",
+					Optional:    true,
+					Computed:    true,
+					Default:     stringdefault.StaticString("imagetest"),
+				},
+			},
+		},
+		"datadog_metrics": schema.SingleNestedAttribute{
+			Description: "Ships the duration of each step, the passed and failed step counts, and the total harness duration to Datadog as custom metrics. The Datadog site is read from the DD_SITE environment variable, defaulting to datadoghq.com.",
+			Optional:    true,
+			Attributes: map[string]schema.Attribute{
+				"api_key": schema.StringAttribute{
+					Description: "The Datadog API key.",
+					Required:    true,
+					Sensitive:   true,
+				},
+				"app_key": schema.StringAttribute{
+					Description: "The Datadog application key.",
+					Optional:    true,
+					Sensitive:   true,
+				},
+				"tags": schema.ListAttribute{
+					Description: "Tags added to every metric, in the key:value form.",
+					Optional:    true,
+					ElementType: types.StringType,
+				},
+			},
+		},
+		"report_to_testmo": schema.SingleNestedAttribute{
+			Description: "Submits the harness steps to Testmo as an automation run. The run is created along with the harness, and the results are submitted once every feature is done with it.",
+			Optional:    true,
+			Attributes: map[string]schema.Attribute{
+				"url": schema.StringAttribute{
+					Description: "The URL of the Testmo instance, e.g. https://example.testmo.net.",
+					Required:    true,
+				},
+				"token": schema.StringAttribute{
+					Description: "The Testmo API token.",
+					Required:    true,
+					Sensitive:   true,
+				},
+				"project_id": schema.Int64Attribute{
+					Description: "The ID of the Testmo project to create the run in.",
+					Required:    true,
+				},
+				"milestone": schema.StringAttribute{
+					Description: "The name of the milestone to link the run to.",
+					Optional:    true,
+				},
+			},
+		},
+		"testmo_run_url": schema.StringAttribute{
+			Description: "The URL of the Testmo run, when report_to_testmo is set.",
+			Computed:    true,
+		},
+		"report_to_allure": schema.SingleNestedAttribute{
+			Description: "Uploads the harness steps to Allure TestOps as a launch once every feature is done with the harness. The output of each step is attached to its result.",
+			Optional:    true,
+			Attributes: map[string]schema.Attribute{
+				"allure_server_url": schema.StringAttribute{
+					Description: "The URL of the Allure TestOps server.",
+					Required:    true,
+				},
+				"project_id": schema.Int64Attribute{
+					Description: "The ID of the Allure TestOps project to create the launch in.",
+					Required:    true,
+				},
+				"token": schema.StringAttribute{
+					Description: "The Allure TestOps API token.",
+					Required:    true,
+					Sensitive:   true,
+				},
+			},
+		},
+		"report_to_jira": schema.SingleNestedAttribute{
+			Description: "Imports the harness steps to a Jira Xray Cloud test execution. The execution is created along with the harness, and a JUnit report of the steps is imported to it once every feature is done with the harness. Only Xray Cloud is supported: results are always sent to the Xray Cloud API, whatever the Jira site.",
+			Optional:    true,
+			Attributes: map[string]schema.Attribute{
+				"jira_url": schema.StringAttribute{
+					Description: "The URL of the Jira Cloud site, only used to link to the test execution. It does not change where the results are sent.",
+					Required:    true,
+				},
+				"client_id": schema.StringAttribute{
+					Description: "The client ID of the Xray API key.",
+					Required:    true,
+					Sensitive:   true,
+				},
+				"client_secret": schema.StringAttribute{
+					Description: "The client secret of the Xray API key.",
+					Required:    true,
+					Sensitive:   true,
+				},
+				"project_key": schema.StringAttribute{
+					Description: "The key of the Jira project to create the test execution in.",
+					Required:    true,
+				},
+				"test_plan_key": schema.StringAttribute{
+					Description: "The key of the test plan to link the test execution to.",
+					Optional:    true,
+				},
+			},
+		},
+		"xray_execution_key": schema.StringAttribute{
+			Description: "The key of the Xray test execution, when report_to_jira is set.",
+			Computed:    true,
+		},
 		"envs": schema.MapAttribute{
 			Description: "Environment variables to set on the container.",
 			Optional:    true,
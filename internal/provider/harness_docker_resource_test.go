@@ -1,9 +1,23 @@
 package provider
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
 
+	cprovider "github.com/chainguard-dev/terraform-provider-imagetest/internal/containers/provider"
+	dockertypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
 
 func TestHarnessDockerResource(t *testing.T) {
@@ -149,6 +163,411 @@ resource "imagetest_feature" "test" {
 				Check: resource.ComposeAggregateTestCheckFunc(),
 			},
 		},
+		"with run as nonroot": {
+			{
+				ExpectNonEmptyPlan: true,
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_harness_docker" "test" {
+  name = "test"
+  inventory = data.imagetest_inventory.this
+  run_as_nonroot = true
+}
+
+resource "imagetest_feature" "test" {
+  name = "Simple Docker based test"
+  description = "Test that steps run as the nobody user"
+  harness = imagetest_harness_docker.test
+  steps = [
+    {
+      name = "Whoami"
+      cmd = "[ \"$(id -u)\" = \"65534\" ]"
+    },
+  ]
+}
+        `,
+				Check: resource.ComposeAggregateTestCheckFunc(),
+			},
+		},
+		"with read only root filesystem": {
+			{
+				ExpectNonEmptyPlan: true,
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_harness_docker" "test" {
+  name = "test"
+  inventory = data.imagetest_inventory.this
+  read_only_root_filesystem = true
+}
+
+resource "imagetest_feature" "test" {
+  name = "Simple Docker based test"
+  description = "Test that the root filesystem is read only but /tmp is writable"
+  harness = imagetest_harness_docker.test
+  steps = [
+    {
+      name = "Root is read only"
+      cmd = "! touch /.testfile"
+    },
+    {
+      name = "Tmp is writable"
+      cmd = "touch /tmp/.testfile"
+    },
+  ]
+}
+        `,
+				Check: resource.ComposeAggregateTestCheckFunc(),
+			},
+		},
+		"with all capabilities dropped": {
+			{
+				ExpectNonEmptyPlan: true,
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_harness_docker" "test" {
+  name = "test"
+  inventory = data.imagetest_inventory.this
+  drop_all_capabilities = true
+  cap_add = ["CHOWN"]
+}
+
+resource "imagetest_feature" "test" {
+  name = "Simple Docker based test"
+  description = "Test that only the added capabilities are granted"
+  harness = imagetest_harness_docker.test
+  steps = [
+    {
+      name = "Chown is allowed"
+      cmd = "touch /tmp/.testfile && chown 65534 /tmp/.testfile"
+    },
+    {
+      name = "Mknod is not allowed"
+      cmd = "! mknod /tmp/.testnode c 1 3"
+    },
+  ]
+}
+        `,
+				Check: resource.ComposeAggregateTestCheckFunc(),
+			},
+		},
+		"with seccomp profile": {
+			{
+				ExpectNonEmptyPlan: true,
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_harness_docker" "test" {
+  name = "test"
+  inventory = data.imagetest_inventory.this
+  seccomp_profile = "unconfined"
+}
+
+resource "imagetest_feature" "test" {
+  name = "Simple Docker based test"
+  description = "Test that the seccomp profile is applied"
+  harness = imagetest_harness_docker.test
+  steps = [
+    {
+      name = "Seccomp is disabled"
+      cmd = "grep -q 'Seccomp:[[:space:]]*0' /proc/self/status"
+    },
+  ]
+}
+        `,
+			},
+		},
+		"with invalid seccomp profile": {
+			{
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_harness_docker" "test" {
+  name = "test"
+  inventory = data.imagetest_inventory.this
+  seccomp_profile = "/does/not/exist.json"
+}
+        `,
+				ExpectError: regexp.MustCompile(`reading seccomp profile`),
+			},
+		},
+		"with runtime default apparmor profile": {
+			{
+				ExpectNonEmptyPlan: true,
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_harness_docker" "test" {
+  name = "test"
+  inventory = data.imagetest_inventory.this
+  apparmor_profile = "runtime/default"
+}
+
+resource "imagetest_feature" "test" {
+  name = "Simple Docker based test"
+  description = "Test that the runtime default AppArmor profile starts the harness"
+  harness = imagetest_harness_docker.test
+  steps = [
+    {
+      name = "Hello"
+      cmd = "echo hello"
+    },
+  ]
+}
+        `,
+			},
+		},
+		"with network egress filter": {
+			{
+				ExpectNonEmptyPlan: true,
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_harness_docker" "test" {
+  name = "test"
+  inventory = data.imagetest_inventory.this
+  network_egress_filter = {
+    allowed_domains = ["cgr.dev"]
+  }
+}
+
+resource "imagetest_feature" "test" {
+  name = "Simple Docker based test"
+  description = "Test that only allowed destinations are reachable"
+  harness = imagetest_harness_docker.test
+  steps = [
+    {
+      name = "Allowed domain"
+      cmd = "wget -O /dev/null https://cgr.dev/v2/ 2>&1 | grep -q '401 Unauthorized'"
+    },
+    {
+      name = "Disallowed domain"
+      cmd = "! wget -q -T 5 -O /dev/null https://example.com"
+    },
+  ]
+}
+        `,
+				Check: resource.ComposeAggregateTestCheckFunc(),
+			},
+		},
+		"with resource budget": {
+			{
+				ExpectNonEmptyPlan: true,
+				ExpectError:        regexp.MustCompile("resource budget exceeded: total_wall_time"),
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_harness_docker" "test" {
+  name = "test"
+  inventory = data.imagetest_inventory.this
+  resource_budget = {
+    total_wall_time = "5s"
+  }
+}
+
+resource "imagetest_feature" "test" {
+  name = "Simple Docker based test"
+  description = "Test that steps are stopped once the budget is exceeded"
+  harness = imagetest_harness_docker.test
+  steps = [
+    {
+      name = "Sleep"
+      cmd = "sleep 30"
+    },
+  ]
+}
+        `,
+			},
+		},
+		"with trace id": {
+			{
+				ExpectNonEmptyPlan: true,
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_harness_docker" "test" {
+  name = "test"
+  inventory = data.imagetest_inventory.this
+  trace_id = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+}
+
+resource "imagetest_feature" "test" {
+  name = "Simple Docker based test"
+  description = "Test that steps run as part of the parent trace"
+  harness = imagetest_harness_docker.test
+  steps = [
+    {
+      name = "Trace parent"
+      cmd = "echo $TRACEPARENT | grep '^00-4bf92f3577b34da6a3ce929d0e0e4736-' && ! echo $TRACEPARENT | grep 00f067aa0ba902b7"
+    },
+  ]
+}
+        `,
+			},
+		},
+		"with invalid pagerduty severity": {
+			{
+				ExpectError: regexp.MustCompile("invalid pagerduty_integration severity"),
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_harness_docker" "test" {
+  name = "test"
+  inventory = data.imagetest_inventory.this
+  pagerduty_integration = {
+    integration_key = "0123456789abcdef0123456789abcdef"
+    severity = "fatal"
+  }
+}
+
+resource "imagetest_feature" "test" {
+  name = "Simple Docker based test"
+  description = "Test that the pagerduty severity is validated"
+  harness = imagetest_harness_docker.test
+  steps = [
+    {
+      name = "Hello"
+      cmd = "echo hello"
+    },
+  ]
+}
+        `,
+			},
+		},
+		"with dry run": {
+			{
+				ExpectNonEmptyPlan: true,
+				Config: `
+provider "imagetest" {
+  dry_run_all = true
+}
+
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_harness_docker" "test" {
+  name = "test"
+  inventory = data.imagetest_inventory.this
+}
+
+resource "imagetest_feature" "test" {
+  name = "Simple Docker based test"
+  description = "Test that nothing runs in dry run mode"
+  harness = imagetest_harness_docker.test
+  steps = [
+    {
+      name = "Fail"
+      cmd = "false"
+    },
+  ]
+}
+        `,
+				Check: resource.TestCheckResourceAttr("imagetest_harness_docker.test", "skipped", "true"),
+			},
+		},
+		"with concurrent resource limit": {
+			{
+				ExpectNonEmptyPlan: true,
+				Config: `
+provider "imagetest" {
+  concurrent_resource_limit = 1
+}
+
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_harness_docker" "test" {
+  name = "test"
+  inventory = data.imagetest_inventory.this
+}
+
+resource "imagetest_feature" "first" {
+  name = "First"
+  description = "Test that resources are created one at a time"
+  harness = imagetest_harness_docker.test
+  steps = [
+    {
+      name = "Hello"
+      cmd = "echo hello"
+    },
+  ]
+}
+
+resource "imagetest_feature" "second" {
+  name = "Second"
+  description = "Test that resources are created one at a time"
+  harness = imagetest_harness_docker.test
+  steps = [
+    {
+      name = "Hello"
+      cmd = "echo hello"
+    },
+  ]
+}
+        `,
+			},
+		},
+		"with derived inventory seed": {
+			{
+				ExpectNonEmptyPlan: true,
+				Config: `
+provider "imagetest" {
+  inventory_seed_source = "random_uuid"
+}
+
+data "imagetest_inventory" "this" {
+  seed = ""
+}
+
+resource "imagetest_harness_docker" "test" {
+  name = "test"
+  inventory = data.imagetest_inventory.this
+}
+
+resource "imagetest_feature" "test" {
+  name = "Simple Docker based test"
+  description = "Test that harnesses work with a derived inventory seed"
+  harness = imagetest_harness_docker.test
+  steps = [
+    {
+      name = "Hello"
+      cmd = "echo hello"
+    },
+  ]
+}
+        `,
+				Check: resource.TestMatchResourceAttr("data.imagetest_inventory.this", "seed", regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-`)),
+			},
+		},
+		"without inventory network": {
+			{
+				ExpectNonEmptyPlan: true,
+				Config: `
+provider "imagetest" {
+  network_create_default = false
+}
+
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_harness_docker" "test" {
+  name = "test"
+  inventory = data.imagetest_inventory.this
+}
+
+resource "imagetest_feature" "test" {
+  name = "Simple Docker based test"
+  description = "Test that harnesses work in the shared default network"
+  harness = imagetest_harness_docker.test
+  steps = [
+    {
+      name = "Hello"
+      cmd = "echo hello"
+    },
+  ]
+}
+        `,
+			},
+		},
 		"docker works": {
 			{
 				ExpectNonEmptyPlan: true,
@@ -221,3 +640,419 @@ resource "imagetest_feature" "test" {
 		})
 	}
 }
+
+func TestHarnessDockerResourceSlackNotification(t *testing.T) {
+	var mu sync.Mutex
+	var messages []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		messages = append(messages, string(body))
+	}))
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				ExpectNonEmptyPlan: true,
+				ExpectError:        regexp.MustCompile("failed to test feature"),
+				Config: fmt.Sprintf(`
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_harness_docker" "test" {
+  name = "test"
+  inventory = data.imagetest_inventory.this
+  slack_notification = {
+    webhook_url = %q
+    channel = "#imagetest"
+    mention_on_failure = ["U0123456789"]
+  }
+}
+
+resource "imagetest_feature" "test" {
+  name = "Simple Docker based test"
+  description = "Test that failures are notified to Slack"
+  harness = imagetest_harness_docker.test
+  steps = [
+    {
+      name = "Fail"
+      cmd = "false"
+    },
+  ]
+}
+        `, srv.URL),
+			},
+		},
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 slack message, got %d", len(messages))
+	}
+	for _, want := range []string{`"channel":"#imagetest"`, "Simple Docker based test / Fail", "<@U0123456789>"} {
+		if !strings.Contains(messages[0], want) {
+			t.Errorf("slack message %s does not contain %s", messages[0], want)
+		}
+	}
+}
+
+func TestHarnessDockerResourceGithubStatus(t *testing.T) {
+	var mu sync.Mutex
+	var statuses []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/chainguard-dev/imagetest/statuses/0123456789abcdef" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		statuses = append(statuses, string(body))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	t.Setenv("GITHUB_API_URL", srv.URL)
+	t.Setenv("GITHUB_SHA", "0123456789abcdef")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				ExpectNonEmptyPlan: true,
+				Config: `
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_harness_docker" "test" {
+  name = "test"
+  inventory = data.imagetest_inventory.this
+  github_status = {
+    token = "token"
+    repo = "chainguard-dev/imagetest"
+  }
+}
+
+resource "imagetest_feature" "test" {
+  name = "Simple Docker based test"
+  description = "Test that the commit status is updated"
+  harness = imagetest_harness_docker.test
+  steps = [
+    {
+      name = "Hello"
+      cmd = "echo hello"
+    },
+  ]
+}
+        `,
+			},
+		},
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(statuses) != 2 {
+		t.Fatalf("expected 2 commit statuses, got %d", len(statuses))
+	}
+	for i, want := range []string{`"state":"pending"`, `"state":"success"`} {
+		if !strings.Contains(statuses[i], want) {
+			t.Errorf("commit status %s does not contain %s", statuses[i], want)
+		}
+	}
+}
+
+func TestHarnessDockerResourceReportToTestmo(t *testing.T) {
+	var mu sync.Mutex
+	var appended string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/api/v1/projects/7/automation/runs":
+			fmt.Fprint(w, `{"id": 42}`)
+		case "/api/v1/automation/runs/42/threads":
+			fmt.Fprint(w, `{"id": 3}`)
+		case "/api/v1/automation/runs/threads/3/append":
+			body, _ := io.ReadAll(r.Body)
+			appended = string(body)
+		case "/api/v1/automation/runs/threads/3/complete", "/api/v1/automation/runs/42/complete":
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				ExpectNonEmptyPlan: true,
+				Config: fmt.Sprintf(`
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_harness_docker" "test" {
+  name = "test"
+  inventory = data.imagetest_inventory.this
+  report_to_testmo = {
+    url = %q
+    token = "token"
+    project_id = 7
+  }
+}
+
+resource "imagetest_feature" "test" {
+  name = "Simple Docker based test"
+  description = "Test that results are submitted to Testmo"
+  harness = imagetest_harness_docker.test
+  steps = [
+    {
+      name = "Hello"
+      cmd = "echo hello"
+    },
+  ]
+}
+        `, srv.URL),
+				Check: resource.TestCheckResourceAttr("imagetest_harness_docker.test", "testmo_run_url", srv.URL+"/automation/runs/view/42"),
+			},
+		},
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !strings.Contains(appended, `"name":"Hello","folder":"Simple Docker based test","status":"passed"`) {
+		t.Errorf("unexpected testmo results: %s", appended)
+	}
+}
+
+func TestHarnessDockerResourceReportToAllure(t *testing.T) {
+	var mu sync.Mutex
+	var uploaded []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/api/uaa/oauth/token":
+			fmt.Fprint(w, `{"access_token": "access"}`)
+		case "/api/rs/launch":
+			fmt.Fprint(w, `{"id": 5}`)
+		case "/api/rs/launch/5/upload/file":
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			for _, fh := range r.MultipartForm.File["file"] {
+				f, _ := fh.Open()
+				data, _ := io.ReadAll(f)
+				f.Close()
+				uploaded = append(uploaded, string(data))
+			}
+		case "/api/rs/launch/5/close":
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				ExpectNonEmptyPlan: true,
+				Config: fmt.Sprintf(`
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_harness_docker" "test" {
+  name = "test"
+  inventory = data.imagetest_inventory.this
+  report_to_allure = {
+    allure_server_url = %q
+    project_id = 1
+    token = "token"
+  }
+}
+
+resource "imagetest_feature" "test" {
+  name = "Simple Docker based test"
+  description = "Test that results are uploaded to Allure"
+  harness = imagetest_harness_docker.test
+  steps = [
+    {
+      name = "Hello"
+      cmd = "echo hello"
+    },
+  ]
+}
+        `, srv.URL),
+			},
+		},
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	all := strings.Join(uploaded, "\n")
+	for _, want := range []string{`"name":"Hello"`, `"status":"passed"`, "hello\n"} {
+		if !strings.Contains(all, want) {
+			t.Errorf("uploaded allure results %s do not contain %q", all, want)
+		}
+	}
+}
+
+func TestHarnessDockerResourceBudgetKillsStep(t *testing.T) {
+	// keep the harness container around to check the step is no longer running
+	t.Setenv("IMAGETEST_SKIP_TEARDOWN", "1")
+	runId := uuid.NewString()
+	t.Setenv("IMAGETEST_TEST_RUN", runId)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				ExpectNonEmptyPlan: true,
+				ExpectError:        regexp.MustCompile("resource budget exceeded: total_wall_time"),
+				Config: `
+provider "imagetest" {
+  container_labels_from_env = ["IMAGETEST_TEST_RUN"]
+}
+
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_harness_docker" "test" {
+  name = "test"
+  inventory = data.imagetest_inventory.this
+  resource_budget = {
+    total_wall_time = "5s"
+  }
+}
+
+resource "imagetest_feature" "test" {
+  name = "Simple Docker based test"
+  description = "Test that steps exceeding the budget are killed"
+  harness = imagetest_harness_docker.test
+  steps = [
+    {
+      name = "Sleep"
+      cmd = "sleep 300"
+    },
+  ]
+}
+        `,
+			},
+		},
+	})
+
+	ctx := context.Background()
+	cli, err := cprovider.NewDockerClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	containers, err := cli.ContainerList(ctx, container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", "terraform.imagetest/IMAGETEST_TEST_RUN="+runId)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(containers) == 0 {
+		t.Fatal("harness container not found")
+	}
+
+	for _, c := range containers {
+		t.Cleanup(func() {
+			_ = cli.ContainerRemove(ctx, c.ID, container.RemoveOptions{Force: true, RemoveVolumes: true})
+		})
+
+		top, err := cli.ContainerTop(ctx, c.ID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range top.Processes {
+			if strings.Contains(strings.Join(p, " "), "sleep 300") {
+				t.Errorf("step is still running in harness container %s: %v", c.ID, p)
+			}
+		}
+	}
+}
+
+func TestHarnessDockerResourceInventoryNetwork(t *testing.T) {
+	runId := uuid.NewString()
+	t.Setenv("IMAGETEST_TEST_RUN", runId)
+	runFilter := filters.Arg("label", "terraform.imagetest/IMAGETEST_TEST_RUN="+runId)
+
+	ctx := context.Background()
+	cli, err := cprovider.NewDockerClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// networks returns the names of the networks created for the test run
+	networks := func() ([]string, error) {
+		list, err := cli.NetworkList(ctx, dockertypes.NetworkListOptions{Filters: filters.NewArgs(runFilter)})
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, n := range list {
+			names = append(names, n.Name)
+		}
+		return names, nil
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: func(*terraform.State) error {
+			names, err := networks()
+			if err != nil {
+				return err
+			}
+			if len(names) > 0 {
+				return fmt.Errorf("inventory networks %v were not removed", names)
+			}
+
+			containers, err := cli.ContainerList(ctx, container.ListOptions{All: true, Filters: filters.NewArgs(runFilter)})
+			if err != nil {
+				return err
+			}
+			if len(containers) > 0 {
+				return fmt.Errorf("%d harness containers were not removed", len(containers))
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				ExpectNonEmptyPlan: true,
+				// a harness without features is only torn down when it is deleted
+				Config: `
+provider "imagetest" {
+  container_labels_from_env = ["IMAGETEST_TEST_RUN"]
+}
+
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_harness_docker" "test" {
+  name = "test"
+  inventory = data.imagetest_inventory.this
+}
+        `,
+				Check: func(*terraform.State) error {
+					names, err := networks()
+					if err != nil {
+						return err
+					}
+					if len(names) != 1 || !strings.HasPrefix(names[0], cprovider.DockerDefaultNetworkName+"-") {
+						return fmt.Errorf("got networks %v, want a single inventory network", names)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
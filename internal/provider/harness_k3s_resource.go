@@ -7,7 +7,6 @@ import (
 	"path/filepath"
 	"time"
 
-	"github.com/chainguard-dev/terraform-provider-imagetest/internal/containers/provider"
 	"github.com/chainguard-dev/terraform-provider-imagetest/internal/harnesses/k3s"
 	"github.com/chainguard-dev/terraform-provider-imagetest/internal/log"
 	"github.com/chainguard-dev/terraform-provider-imagetest/internal/util"
@@ -96,6 +95,13 @@ func (r *HarnessK3sResource) Schema(ctx context.Context, _ resource.SchemaReques
 }
 
 func (r *HarnessK3sResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	release, diags := r.store.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
+	defer release()
+
 	ctx = log.WithCtx(ctx, r.store.Logger())
 
 	var data HarnessK3sResourceModel
@@ -115,7 +121,7 @@ func (r *HarnessK3sResource) Create(ctx context.Context, req resource.CreateRequ
 		return
 	}
 
-	timeout, diags := data.Timeouts.Create(ctx, defaultHarnessK3sCreateTimeout)
+	timeout, diags := data.Timeouts.Create(ctx, r.store.Timeout(defaultHarnessK3sCreateTimeout))
 	resp.Diagnostics.Append(diags...)
 
 	ctx, cancel := context.WithTimeout(ctx, timeout)
@@ -224,10 +230,16 @@ func (r *HarnessK3sResource) Create(ctx context.Context, req resource.CreateRequ
 	kopts = append(kopts, k3s.WithNetworks(networks...))
 
 	id := data.Id.ValueString()
+	if r.DryRun(resp, id) {
+		data.Skipped = types.BoolValue(true)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
 	configVolumeName := id + "-config"
 
 	_, err := r.store.cli.VolumeCreate(ctx, volume.CreateOptions{
-		Labels: provider.DefaultLabels,
+		Labels: r.store.cli.Labels(),
 		Name:   configVolumeName,
 	})
 	if err != nil {
@@ -272,6 +284,13 @@ func (r *HarnessK3sResource) Read(ctx context.Context, req resource.ReadRequest,
 }
 
 func (r *HarnessK3sResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	release, diags := r.store.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
+	defer release()
+
 	var data HarnessK3sResourceModel
 
 	// Read Terraform plan data into the model
@@ -286,6 +305,13 @@ func (r *HarnessK3sResource) Update(ctx context.Context, req resource.UpdateRequ
 }
 
 func (r *HarnessK3sResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	release, diags := r.store.acquire(ctx)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
+	defer release()
+
 	var data HarnessK3sResourceModel
 
 	// Read Terraform prior state data into the model
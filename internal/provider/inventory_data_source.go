@@ -2,9 +2,14 @@ package provider
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"os/exec"
+	"strings"
+	"time"
 
 	"github.com/chainguard-dev/terraform-provider-imagetest/internal/log"
+	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -19,6 +24,14 @@ func NewInventoryDataSource() datasource.DataSource {
 	return &InventoryDataSource{}
 }
 
+// Inventory seed sources, see the provider inventory_seed_source attribute.
+const (
+	InventorySeedSourceExplicit          = "explicit"
+	InventorySeedSourceGitCommitSha      = "git_commit_sha"
+	InventorySeedSourceRandomUuid        = "random_uuid"
+	InventorySeedSourceHostnameTimestamp = "hostname_timestamp"
+)
+
 // InventoryDataSource defines the data source implementation.
 type InventoryDataSource struct {
 	store *ProviderStore
@@ -39,7 +52,9 @@ func (d *InventoryDataSource) Schema(_ context.Context, _ datasource.SchemaReque
 		MarkdownDescription: "Inventory data source. Keeps track of harness resources.",
 		Attributes: map[string]schema.Attribute{
 			"seed": schema.StringAttribute{
-				Computed: true,
+				Description: "The seed of the inventory, used to derive the IDs of the resources created within it. A unique seed is generated when unset. When set to an empty string, the seed is derived as configured by the provider inventory_seed_source. The inventory is reset every time the data source is read, so runs sharing a seed do not share their harnesses. Seeds are never used as file paths: the inventory is kept in the provider temporary directory under a name derived from the seed.",
+				Optional:    true,
+				Computed:    true,
 			},
 		},
 	}
@@ -69,14 +84,12 @@ func (d *InventoryDataSource) Read(ctx context.Context, req datasource.ReadReque
 		return
 	}
 
-	f, err := os.CreateTemp("", "imagetest-")
+	seed, err := d.seed(ctx, data.Seed)
 	if err != nil {
-		resp.Diagnostics.AddError("failed to create temp file", err.Error())
+		resp.Diagnostics.AddError("failed to derive inventory seed", err.Error())
 		return
 	}
-	defer f.Close()
-
-	data.Seed = types.StringValue(f.Name())
+	data.Seed = types.StringValue(seed)
 
 	if err := d.store.Inventory(data).Create(ctx); err != nil {
 		resp.Diagnostics.AddError("failed to create inventory", err.Error())
@@ -86,3 +99,49 @@ func (d *InventoryDataSource) Read(ctx context.Context, req datasource.ReadReque
 	// Save data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
+
+// seed returns the inventory seed. A seed set by the user is used as is, an
+// empty seed is derived from the provider inventory_seed_source, and a unique
+// file in the inventory directory is used otherwise.
+func (d *InventoryDataSource) seed(ctx context.Context, seed types.String) (string, error) {
+	if !seed.IsNull() && !seed.IsUnknown() && seed.ValueString() != "" {
+		return seed.ValueString(), nil
+	}
+
+	source := InventorySeedSourceExplicit
+	if seed.ValueString() == "" && !seed.IsNull() {
+		source = d.store.seedSource
+	}
+
+	switch source {
+	case InventorySeedSourceGitCommitSha:
+		out, err := exec.CommandContext(ctx, "git", "rev-parse", "HEAD").Output()
+		if err != nil {
+			return "", fmt.Errorf("running git rev-parse HEAD: %w", err)
+		}
+		return strings.TrimSpace(string(out)), nil
+
+	case InventorySeedSourceRandomUuid:
+		return uuid.NewString(), nil
+
+	case InventorySeedSourceHostnameTimestamp:
+		hostname, err := os.Hostname()
+		if err != nil {
+			return "", fmt.Errorf("getting hostname: %w", err)
+		}
+		return fmt.Sprintf("%s-%d", hostname, time.Now().Unix()), nil
+
+	default:
+		if err := os.MkdirAll(InventoryDir(), 0755); err != nil {
+			return "", fmt.Errorf("creating inventory directory: %w", err)
+		}
+
+		f, err := os.CreateTemp(InventoryDir(), "imagetest-")
+		if err != nil {
+			return "", fmt.Errorf("creating temp file: %w", err)
+		}
+		defer f.Close()
+
+		return f.Name(), nil
+	}
+}
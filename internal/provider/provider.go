@@ -2,14 +2,20 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
 
 	cprovider "github.com/chainguard-dev/terraform-provider-imagetest/internal/containers/provider"
+	"github.com/docker/docker/client"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"golang.org/x/sync/semaphore"
 )
 
 var _ provider.Provider = &ImageTestProvider{}
@@ -28,6 +34,17 @@ type ImageTestProviderModel struct {
 	Log       *ProviderLoggerModel           `tfsdk:"log"`
 	Harnesses *ImageTestProviderHarnessModel `tfsdk:"harnesses"`
 	Labels    types.Map                      `tfsdk:"labels"`
+	// AuditLogBackend is where the Docker API calls are logged, one of file,
+	// stdout or http.
+	AuditLogBackend         types.String `tfsdk:"audit_log_backend"`
+	AuditLogDestination     types.String `tfsdk:"audit_log_destination"`
+	DryRunAll               types.Bool   `tfsdk:"dry_run_all"`
+	ConcurrentResourceLimit types.Int64  `tfsdk:"concurrent_resource_limit"`
+	ResourceTimeoutDefault  types.String `tfsdk:"resource_timeout_default"`
+	InventorySeedSource     types.String `tfsdk:"inventory_seed_source"`
+	ContainerLabelsFromEnv  []string     `tfsdk:"container_labels_from_env"`
+	NetworkCreateDefault    types.Bool   `tfsdk:"network_create_default"`
+	VolumeCleanupStrategy   types.String `tfsdk:"volume_cleanup_strategy"`
 }
 
 type ImageTestProviderHarnessModel struct {
@@ -73,6 +90,43 @@ func (p *ImageTestProvider) Schema(ctx context.Context, req provider.SchemaReque
 				ElementType: types.StringType,
 				Optional:    true,
 			},
+			"dry_run_all": schema.BoolAttribute{
+				Description: "Validates every resource without creating anything on the Docker daemon. Harnesses are marked as skipped, and so are the features using them.",
+				Optional:    true,
+			},
+			"concurrent_resource_limit": schema.Int64Attribute{
+				Description: "The maximum number of resources created, updated or deleted at the same time, regardless of the Terraform parallelism. Unlimited when unset.",
+				Optional:    true,
+			},
+			"resource_timeout_default": schema.StringAttribute{
+				Description: "The create timeout of the resources that do not set their own, as a duration, e.g. 30m.",
+				Optional:    true,
+			},
+			"inventory_seed_source": schema.StringAttribute{
+				Description: "How the seed of inventories with an empty seed is derived, one of: explicit (a unique seed, as if unset), git_commit_sha (the output of git rev-parse HEAD), random_uuid, hostname_timestamp. Defaults to explicit.",
+				Optional:    true,
+			},
+			"container_labels_from_env": schema.ListAttribute{
+				Description: "The names of environment variables to add as labels to every Docker object created by the provider, as terraform.imagetest/<name>=<value>. Variables that are not set are skipped.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"network_create_default": schema.BoolAttribute{
+				Description: "Whether to create a bridge network per inventory, named after the inventory seed. Docker and container harnesses that don't specify any networks are created in it instead of the network shared by every inventory; k3s harnesses are unaffected. The network is removed once the last harness of the inventory is torn down. Defaults to true.",
+				Optional:    true,
+			},
+			"volume_cleanup_strategy": schema.StringAttribute{
+				Description: "The cleanup_strategy of the imagetest_container_volume resources that don't set their own, one of: destroy, keep, keep_on_failure. Defaults to destroy.",
+				Optional:    true,
+			},
+			"audit_log_backend": schema.StringAttribute{
+				Description: "Logs every Docker API call made by the provider as JSON to the given backend, one of: file, stdout, http. Each entry holds the method, endpoint, resource ID, timestamp, and outcome of the call. Each entry is written before the call returns, the http backend waiting at most 10 seconds, and a failing backend never fails the calls. The stdout backend writes to the provider output, which Terraform only shows in its logs when TF_LOG is set. The exec and attach streams that run the steps are not logged, only the calls that create and inspect them. Audit logging is not supported for TLS connections to the Docker daemon, configuring the provider fails in that case.",
+				Optional:    true,
+			},
+			"audit_log_destination": schema.StringAttribute{
+				Description: "The path of the file to append to with the file backend, or the URL to post each entry to with the http backend.",
+				Optional:    true,
+			},
 			"log": schema.SingleNestedAttribute{
 				Optional: true,
 				Attributes: map[string]schema.Attribute{
@@ -270,8 +324,64 @@ func (p *ImageTestProvider) Configure(ctx context.Context, req provider.Configur
 		return
 	}
 	p.store.labels = labels
+	p.store.dryRun = data.DryRunAll.ValueBool()
+	p.store.inventoryNetworks = data.NetworkCreateDefault.IsNull() || data.NetworkCreateDefault.ValueBool()
 
-	cli, err := cprovider.NewDockerClient()
+	if !data.ConcurrentResourceLimit.IsNull() {
+		limit := data.ConcurrentResourceLimit.ValueInt64()
+		if limit < 1 {
+			resp.Diagnostics.AddError("invalid provider input", fmt.Sprintf("concurrent_resource_limit must be at least 1, got %d", limit))
+			return
+		}
+		p.store.inflight = semaphore.NewWeighted(limit)
+	}
+
+	p.store.seedSource = InventorySeedSourceExplicit
+	if !data.InventorySeedSource.IsNull() {
+		switch source := data.InventorySeedSource.ValueString(); source {
+		case InventorySeedSourceExplicit, InventorySeedSourceGitCommitSha, InventorySeedSourceRandomUuid, InventorySeedSourceHostnameTimestamp:
+			p.store.seedSource = source
+		default:
+			resp.Diagnostics.AddError("invalid provider input", fmt.Sprintf("invalid inventory_seed_source %q, must be one of: explicit, git_commit_sha, random_uuid, hostname_timestamp", source))
+			return
+		}
+	}
+
+	p.store.volumeCleanupStrategy = VolumeCleanupStrategyDestroy
+	if !data.VolumeCleanupStrategy.IsNull() {
+		strategy := data.VolumeCleanupStrategy.ValueString()
+		if !validVolumeCleanupStrategy(strategy) {
+			resp.Diagnostics.AddError("invalid provider input", fmt.Sprintf("invalid volume_cleanup_strategy %q, must be one of: destroy, keep, keep_on_failure", strategy))
+			return
+		}
+		p.store.volumeCleanupStrategy = strategy
+	}
+
+	if !data.ResourceTimeoutDefault.IsNull() {
+		d, err := time.ParseDuration(data.ResourceTimeoutDefault.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError("invalid provider input", fmt.Sprintf("invalid resource_timeout_default: %s", err))
+			return
+		}
+		p.store.defaultTimeout = d
+	}
+
+	if err := configureTracerProvider(ctx); err != nil {
+		resp.Diagnostics.AddError("failed to configure tracing", err.Error())
+		return
+	}
+
+	var opts []client.Opt
+	if !data.AuditLogBackend.IsNull() {
+		w, err := auditLogWriter(data.AuditLogBackend.ValueString(), data.AuditLogDestination.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError("invalid provider input", err.Error())
+			return
+		}
+		opts = append(opts, cprovider.WithAuditLog(w))
+	}
+
+	cli, err := cprovider.NewDockerClient(opts...)
 	if err != nil {
 		resp.Diagnostics.AddError("failed to create docker client", err.Error())
 		return
@@ -279,6 +389,14 @@ func (p *ImageTestProvider) Configure(ctx context.Context, req provider.Configur
 	}
 	p.store.cli = cli
 
+	envLabels := make(map[string]string)
+	for _, name := range data.ContainerLabelsFromEnv {
+		if v, ok := os.LookupEnv(name); ok {
+			envLabels["terraform.imagetest/"+name] = v
+		}
+	}
+	cli.AddLabels(envLabels)
+
 	// Store any "global" provider configuration in the store
 	p.store.providerResourceData = data
 
@@ -286,6 +404,26 @@ func (p *ImageTestProvider) Configure(ctx context.Context, req provider.Configur
 	resp.ResourceData = p.store
 }
 
+// auditLogWriter returns the writer of the given audit log backend.
+func auditLogWriter(backend string, destination string) (io.Writer, error) {
+	switch backend {
+	case "stdout":
+		return os.Stdout, nil
+	case "file":
+		if destination == "" {
+			return nil, fmt.Errorf("audit_log_destination must be set to a file path with the file audit log backend")
+		}
+		return &cprovider.FileAuditWriter{Path: destination}, nil
+	case "http":
+		if destination == "" {
+			return nil, fmt.Errorf("audit_log_destination must be set to a URL with the http audit log backend")
+		}
+		return &cprovider.HTTPAuditWriter{URL: destination}, nil
+	default:
+		return nil, fmt.Errorf("invalid audit_log_backend %q, must be one of: file, stdout, http", backend)
+	}
+}
+
 func (p *ImageTestProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewFeatureResource,
@@ -1,10 +1,15 @@
 package provider
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 )
 
 // testAccProtoV6ProviderFactories are used to instantiate a provider during
@@ -20,3 +25,138 @@ func testAccPreCheck(t *testing.T) {
 	// about the appropriate environment variables being set are common to see in a pre-check
 	// function.
 }
+
+func TestProviderAuditLog(t *testing.T) {
+	auditLog := filepath.Join(t.TempDir(), "audit.log")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				ExpectNonEmptyPlan: true,
+				Config: fmt.Sprintf(`
+provider "imagetest" {
+  audit_log_backend = "file"
+  audit_log_destination = %q
+}
+
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_harness_docker" "test" {
+  name = "test"
+  inventory = data.imagetest_inventory.this
+}
+
+resource "imagetest_feature" "test" {
+  name = "Simple Docker based test"
+  description = "Test that Docker API calls are audited"
+  harness = imagetest_harness_docker.test
+  steps = [
+    {
+      name = "Hello"
+      cmd = "echo hello"
+    },
+  ]
+}
+        `, auditLog),
+			},
+		},
+	})
+
+	data, err := os.ReadFile(auditLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"method":"POST","endpoint":"/containers/create"`, `"endpoint":"/exec/`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("audit log does not contain %s", want)
+		}
+	}
+}
+
+func TestProviderDryRunDestroy(t *testing.T) {
+	auditLog := filepath.Join(t.TempDir(), "audit.log")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				ExpectNonEmptyPlan: true,
+				Config: fmt.Sprintf(`
+provider "imagetest" {
+  dry_run_all = true
+  audit_log_backend = "file"
+  audit_log_destination = %q
+}
+
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_harness_docker" "test" {
+  name = "docker"
+  inventory = data.imagetest_inventory.this
+}
+
+resource "imagetest_harness_container" "test" {
+  name = "container"
+  inventory = data.imagetest_inventory.this
+}
+        `, auditLog),
+			},
+		},
+	})
+
+	// the harnesses are created and destroyed without changing anything
+	data, err := os.ReadFile(auditLog)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	for _, method := range []string{"POST", "PUT", "DELETE"} {
+		if strings.Contains(string(data), `"method":"`+method+`"`) {
+			t.Errorf("dry run made %s calls to the Docker API: %s", method, data)
+		}
+	}
+}
+
+func TestProviderContainerLabelsFromEnv(t *testing.T) {
+	t.Setenv("IMAGETEST_PIPELINE_ID", "1234")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				ExpectNonEmptyPlan: true,
+				Config: `
+provider "imagetest" {
+  container_labels_from_env = ["IMAGETEST_PIPELINE_ID", "IMAGETEST_UNSET"]
+}
+
+data "imagetest_inventory" "this" {}
+
+resource "imagetest_harness_docker" "test" {
+  name = "test"
+  inventory = data.imagetest_inventory.this
+}
+
+resource "imagetest_feature" "test" {
+  name = "Simple Docker based test"
+  description = "Test that labels are read from the environment"
+  harness = imagetest_harness_docker.test
+  steps = [
+    {
+      name = "Labels"
+      cmd = <<EOF
+        docker inspect $(hostname) --format '{{ json .Config.Labels }}' > /tmp/labels
+        grep -q '"terraform.imagetest/IMAGETEST_PIPELINE_ID":"1234"' /tmp/labels
+        ! grep -q IMAGETEST_UNSET /tmp/labels
+      EOF
+    },
+  ]
+}
+        `,
+			},
+		},
+	})
+}
@@ -1,26 +1,61 @@
 package provider
 
 import (
+	"context"
 	"crypto/sha256"
+	"fmt"
 	"log/slog"
 	"math/big"
 	"os"
+	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/chainguard-dev/terraform-provider-imagetest/internal/containers/provider"
 	"github.com/chainguard-dev/terraform-provider-imagetest/internal/inventory"
 	"github.com/chainguard-dev/terraform-provider-imagetest/internal/log"
+	"github.com/chainguard-dev/terraform-provider-imagetest/internal/notify"
 	"github.com/chainguard-dev/terraform-provider-imagetest/internal/types"
+	"github.com/docker/docker/client"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	slogmulti "github.com/samber/slog-multi"
+	"golang.org/x/sync/semaphore"
 )
 
+// notifyTimeout bounds the notifications sent once a harness run is finished.
+const notifyTimeout = time.Minute
+
 // ProviderStore manages the global runtime state of the provider. The provider
 // uses this to lookup the defined relationships between resources, and manage
 // shared external state.
 type ProviderStore struct {
 	// harnesses stores a map of the available harnesses, keyed by their ID.
 	harnesses *smap[string, types.Harness]
-	labels    map[string]string
+	// runs stores the outcome of the harnesses being run, keyed by their ID.
+	runs   *smap[string, *notify.Run]
+	labels map[string]string
+	// dryRun is set when resources should be validated without making any
+	// changes to the Docker daemon.
+	dryRun bool
+	// inflight limits the number of resource operations running at the same
+	// time, nil when unlimited.
+	inflight *semaphore.Weighted
+	// defaultTimeout overrides the default create timeout of the resources,
+	// zero to use the resource default.
+	defaultTimeout time.Duration
+	// seedSource is how empty inventory seeds are derived.
+	seedSource string
+	// volumes stores the volume resources, keyed by their ID, to look up how
+	// the volumes mounted by harnesses are cleaned up.
+	volumes *smap[string, ContainerVolumeResourceModel]
+	// failedHarnesses stores the IDs of the harnesses with failed features.
+	failedHarnesses *smap[string, bool]
+	// volumeCleanupStrategy is the cleanup strategy of the volume resources
+	// that don't set their own.
+	volumeCleanupStrategy string
+	// inventoryNetworks is set when harnesses are created in a network
+	// dedicated to their inventory.
+	inventoryNetworks bool
 	// providerResourceData stores the data for the provider resource.
 	// TODO: there's probably a way to do this without passing around the whole
 	// model
@@ -29,12 +64,21 @@ type ProviderStore struct {
 	// cli is the Docker client. it is initialized once during the providers
 	// Configure() stage and reused for any resource that requires it.
 	cli *provider.DockerClient
+
+	// pulledImages tracks the images pulled by the harnesses. resources are
+	// instantiated per request, so this lives in the store to be shared
+	// across every harness created by the provider.
+	pulledImages sync.Map
 }
 
 func NewProviderStore() *ProviderStore {
 	return &ProviderStore{
 		labels:    make(map[string]string),
 		harnesses: newSmap[string, types.Harness](),
+		runs:      newSmap[string, *notify.Run](),
+
+		volumes:         newSmap[string, ContainerVolumeResourceModel](),
+		failedHarnesses: newSmap[string, bool](),
 	}
 }
 
@@ -66,10 +110,172 @@ func (s *ProviderStore) Encode(components ...string) (string, error) {
 	return hashint.Text(36)[:5], nil
 }
 
+// InventoryDir is the directory file inventories are kept in.
+func InventoryDir() string {
+	return filepath.Join(os.TempDir(), "imagetest-inventories")
+}
+
 // Inventory returns an instance of the inventory per inventory data source.
 func (s *ProviderStore) Inventory(data InventoryDataSourceModel) inventory.Inventory {
 	// TODO: More backends?
-	return inventory.NewFile(data.Seed.ValueString())
+	seed := data.Seed.ValueString()
+	if filepath.IsAbs(seed) && filepath.Dir(seed) == InventoryDir() {
+		// the seed is a file the provider generated for the inventory
+		return inventory.NewFile(seed)
+	}
+
+	// any other seed, including user paths that must never be written to, is
+	// kept in the inventory directory under a name derived from the seed.
+	// Deterministic seeds map to the same file across runs, which the
+	// inventory data source resets when it is read.
+	enc, _ := s.Encode(seed)
+	return inventory.NewFile(filepath.Join(InventoryDir(), "imagetest-"+enc))
+}
+
+// InventoryNetwork returns the name of the network dedicated to the given
+// inventory, empty when harnesses use the shared default network.
+func (s *ProviderStore) InventoryNetwork(data InventoryDataSourceModel) (string, error) {
+	if !s.inventoryNetworks {
+		return "", nil
+	}
+
+	enc, err := s.Encode(data.Seed.ValueString())
+	if err != nil {
+		return "", err
+	}
+
+	return provider.DockerDefaultNetworkName + "-" + enc, nil
+}
+
+// RemoveInventoryNetwork removes the network dedicated to the inventory once
+// no harnesses remain in it.
+func (s *ProviderStore) RemoveInventoryNetwork(ctx context.Context, inv InventoryDataSourceModel) error {
+	name, err := s.InventoryNetwork(inv)
+	if err != nil || name == "" {
+		return err
+	}
+
+	harnesses, err := s.Inventory(inv).GetHarnesses(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(harnesses) > 0 {
+		return nil
+	}
+
+	return s.cli.RemoveNetwork(ctx, name)
+}
+
+// RemoveVolume removes the volume with the given ID according to its reclaim
+// policy, cleanup strategy and retention policy. failed is set when a feature
+// of a harness mounting it failed. The pre delete hook of the volume runs right
+// before it is removed. Volumes that weren't created by a volume resource are
+// always removed.
+func (s *ProviderStore) RemoveVolume(ctx context.Context, id string, failed bool) error {
+	force := false
+	if vol, ok := s.volumes.Get(id); ok {
+		switch vol.ReclaimPolicy.ValueString() {
+		case VolumeReclaimPolicyRetain:
+			return nil
+		case VolumeReclaimPolicyDelete:
+			force = true
+		}
+
+		strategy := s.volumeCleanupStrategy
+		if !vol.CleanupStrategy.IsNull() {
+			strategy = vol.CleanupStrategy.ValueString()
+		}
+
+		switch strategy {
+		case VolumeCleanupStrategyKeep:
+			return nil
+		case VolumeCleanupStrategyKeepOnFailure:
+			if failed {
+				return nil
+			}
+		}
+
+		// the helper containers below would create a missing volume when
+		// mounting it, so check it still exists first
+		if _, err := s.cli.VolumeInspect(ctx, id); err != nil {
+			if client.IsErrNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("inspecting volume %s: %w", id, err)
+		}
+
+		switch vol.RetentionPolicy.ValueString() {
+		case VolumeRetentionPolicyRetain:
+			return nil
+		case VolumeRetentionPolicyRetainIfNonEmpty:
+			empty, err := volumeEmpty(ctx, s.cli, id)
+			if err != nil {
+				return err
+			}
+			if !empty {
+				log.Info(ctx, "retaining non empty volume", "volume", id)
+				return nil
+			}
+		}
+
+		if err := runPreDeleteHook(ctx, s.cli, vol); err != nil {
+			return err
+		}
+	}
+
+	return s.cli.RemoveVolume(ctx, id, force)
+}
+
+// volumeRemover returns the func removing the volumes mounted by the harness
+// with the given ID.
+func (s *ProviderStore) volumeRemover(harnessId string) func(context.Context, string) error {
+	return func(ctx context.Context, name string) error {
+		failed, _ := s.failedHarnesses.Get(harnessId)
+		return s.RemoveVolume(ctx, name, failed)
+	}
+}
+
+// FinishRun notifies the outcome of the run of the harness with the given ID,
+// if it is still open. err is the error the harness failed with, if any. The
+// notifications don't share the deadline of ctx, so a timed out feature is
+// still notified.
+func (s *ProviderStore) FinishRun(ctx context.Context, harnessId string, err error) error {
+	run, ok := s.runs.Get(harnessId)
+	if !ok {
+		return nil
+	}
+	s.runs.Delete(harnessId)
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), notifyTimeout)
+	defer cancel()
+
+	return run.Finish(ctx, err)
+}
+
+// acquire blocks until a resource operation may run, returning the func that
+// must be called once it is done.
+func (s *ProviderStore) acquire(ctx context.Context) (func(), diag.Diagnostics) {
+	if s.inflight == nil {
+		return func() {}, nil
+	}
+
+	if err := s.inflight.Acquire(ctx, 1); err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("failed waiting for a free resource slot", err.Error())
+		return nil, diags
+	}
+
+	return func() { s.inflight.Release(1) }, nil
+}
+
+// Timeout returns the default create timeout of a resource, def unless the
+// provider overrides it.
+func (s *ProviderStore) Timeout(def time.Duration) time.Duration {
+	if s.defaultTimeout > 0 {
+		return s.defaultTimeout
+	}
+	return def
 }
 
 // SkipTeardown returns true if the IMAGETEST_SKIP_TEARDOWN environment
@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chainguard-dev/terraform-provider-imagetest/internal/inventory"
+	"github.com/chainguard-dev/terraform-provider-imagetest/internal/notify"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestProviderStoreInventoryUserSeed(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("TMPDIR", t.TempDir())
+
+	// a user seed that happens to be the path of a file they own
+	notes := filepath.Join(t.TempDir(), "notes")
+	if err := os.WriteFile(notes, []byte("my notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewProviderStore()
+	inv := s.Inventory(InventoryDataSourceModel{Seed: types.StringValue(notes)})
+	if err := inv.Create(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := inv.AddHarness(ctx, inventory.Harness("test")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(notes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "my notes" {
+		t.Errorf("user seed file was overwritten with %q", got)
+	}
+
+	entries, err := os.ReadDir(InventoryDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("got %d inventories in %s, want 1", len(entries), InventoryDir())
+	}
+}
+
+func TestProviderStoreInventoryGeneratedSeed(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("TMPDIR", t.TempDir())
+
+	d := &InventoryDataSource{store: NewProviderStore()}
+	seed, err := d.seed(ctx, types.StringNull())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(seed) != InventoryDir() {
+		t.Fatalf("generated seed %s is not in %s", seed, InventoryDir())
+	}
+
+	inv := d.store.Inventory(InventoryDataSourceModel{Seed: types.StringValue(seed)})
+	if err := inv.Create(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "{}\n" {
+		t.Errorf("generated seed file = %q, want the inventory", got)
+	}
+}
+
+type ctxNotifier struct {
+	finished int
+	err      error
+}
+
+func (n *ctxNotifier) Started(context.Context, string) error {
+	return nil
+}
+
+func (n *ctxNotifier) Finished(ctx context.Context, _ notify.Result) error {
+	n.finished++
+	n.err = ctx.Err()
+	return nil
+}
+
+func TestProviderStoreFinishRun(t *testing.T) {
+	n := &ctxNotifier{}
+	s := NewProviderStore()
+	s.runs.Set("harness", notify.NewRun("harness", n))
+
+	// the feature that finishes the run timed out
+	ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+
+	if err := s.FinishRun(ctx, "harness", nil); err != nil {
+		t.Fatal(err)
+	}
+	if n.finished != 1 {
+		t.Fatalf("notifier finished %d times, want 1", n.finished)
+	}
+	if n.err != nil {
+		t.Errorf("notifier context error = %v, want none", n.err)
+	}
+
+	// the run is only finished once
+	if err := s.FinishRun(ctx, "harness", nil); err != nil {
+		t.Fatal(err)
+	}
+	if n.finished != 1 {
+		t.Errorf("notifier finished %d times, want 1", n.finished)
+	}
+}
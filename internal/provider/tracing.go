@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// newTraceExporter creates the exporter of the provider spans.
+var newTraceExporter = func(ctx context.Context) (sdktrace.SpanExporter, error) {
+	return otlptracehttp.New(ctx)
+}
+
+var (
+	configureTracing    sync.Once
+	configureTracingErr error
+)
+
+// configureTracerProvider sets the global tracer provider, which creates the
+// harness spans, to export spans over OTLP/HTTP when an OTLP endpoint is set
+// with the standard OTEL_EXPORTER_OTLP_* environment variables. Otherwise the
+// global provider is left as is, and the steps see the span context of the
+// caller trace since no harness span is recorded. The tracer provider is
+// process wide, so it is only configured once and later calls return the
+// outcome of the first one.
+func configureTracerProvider(ctx context.Context) error {
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return nil
+	}
+
+	configureTracing.Do(func() {
+		exporter, err := newTraceExporter(ctx)
+		if err != nil {
+			configureTracingErr = fmt.Errorf("creating OTLP trace exporter: %w", err)
+			return
+		}
+
+		// harnesses are few and long lived, so export each span as soon as it
+		// ends rather than risking it being dropped when the provider exits
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	})
+
+	return configureTracingErr
+}
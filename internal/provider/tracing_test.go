@@ -0,0 +1,30 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestConfigureTracerProviderError(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318")
+
+	prev := newTraceExporter
+	newTraceExporter = func(context.Context) (sdktrace.SpanExporter, error) {
+		return nil, errors.New("broken exporter")
+	}
+	t.Cleanup(func() {
+		newTraceExporter = prev
+		configureTracing = sync.Once{}
+		configureTracingErr = nil
+	})
+
+	for i := range 2 {
+		if err := configureTracerProvider(context.Background()); err == nil {
+			t.Errorf("call %d of configureTracerProvider() with a broken exporter succeeded", i+1)
+		}
+	}
+}
@@ -57,3 +57,18 @@ type Step interface {
 	Fn() StepFn
 	Level() Level
 }
+
+type stepOutputKey struct{}
+
+// WithStepOutput returns a context carrying the output of the step that
+// returns it.
+func WithStepOutput(ctx context.Context, output string) context.Context {
+	return context.WithValue(ctx, stepOutputKey{}, output)
+}
+
+// StepOutput returns the output carried by the context returned from a step,
+// if the harness reports it.
+func StepOutput(ctx context.Context) string {
+	output, _ := ctx.Value(stepOutputKey{}).(string)
+	return output
+}